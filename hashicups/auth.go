@@ -1,35 +1,42 @@
 package hashicups
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 )
 
-// SignIn - Get a new token for user
-func (c *Client) SignIn() (*AuthResponse, error) {
-	if c.Auth.Username == "" || c.Auth.Password == "" {
+// SignIn - Get a new token for user. Signing in only issues a token, so the
+// request is retried like a read, at most maxSignInRetries times.
+func (c *Client) SignIn(ctx context.Context) (*AuthResponse, error) {
+	return c.signIn(ctx, c.Auth)
+}
+
+// signIn gets a new token for the user of auth.
+func (c *Client) signIn(ctx context.Context, auth AuthStruct) (*AuthResponse, error) {
+	if auth.Username == "" || auth.Password == "" {
 		return nil, fmt.Errorf("define username and password")
 	}
-	rb, err := json.Marshal(c.Auth)
+	rb, err := c.encode(auth)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/signin", c.HostURL), strings.NewReader(string(rb)))
+	ctx = context.WithValue(ctx, withoutCredentialsKey{}, true)
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/signin", c.baseURL()), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := c.doRequest(req)
+	body, err := c.retryRequestWithPolicy(ctx, req, retryPolicy{retryAnyMethod: true, maxRetries: maxSignInRetries})
 	if err != nil {
 		return nil, err
 	}
 
 	ar := AuthResponse{}
-	err = json.Unmarshal(body, &ar)
+	err = c.decode(body, &ar)
 	if err != nil {
 		return nil, err
 	}
@@ -37,9 +44,13 @@ func (c *Client) SignIn() (*AuthResponse, error) {
 	return &ar, nil
 }
 
+// maxSignInRetries bounds the retries of signing in below RetryMax, so an
+// unreachable API fails provider configuration quickly.
+const maxSignInRetries = 3
+
 // SignOut - Revoke the token for a user
-func (c *Client) SignOut() error {
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/signout", c.HostURL), strings.NewReader(string("")))
+func (c *Client) SignOut(ctx context.Context) error {
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/signout", c.baseURL()), strings.NewReader(string("")))
 	if err != nil {
 		return err
 	}
@@ -49,9 +60,39 @@ func (c *Client) SignOut() error {
 		return err
 	}
 
-	if string(body) != "Signed out user" {
+	if len(body) > 0 && string(body) != "Signed out user" {
 		return errors.New(string(body))
 	}
 
 	return nil
 }
+
+// GetMe - Returns the authenticated user, read once per client
+func (c *Client) GetMe(ctx context.Context) (*User, error) {
+	c.meMu.Lock()
+	defer c.meMu.Unlock()
+
+	if c.me != nil {
+		return c.me, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/me", c.baseURL()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	user := User{}
+	err = c.decode(body, &user)
+	if err != nil {
+		return nil, err
+	}
+
+	c.me = &user
+
+	return c.me, nil
+}
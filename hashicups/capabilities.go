@@ -0,0 +1,82 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Optional server features reported by the capabilities endpoint.
+const (
+	// CapabilityGzipRequests means the server accepts gzip encoded request
+	// bodies.
+	CapabilityGzipRequests = "gzip_requests"
+	// CapabilityCoffeeFilters means the server filters coffees by the name,
+	// min_price, and max_price query parameters.
+	CapabilityCoffeeFilters = "coffee_filters"
+	// CapabilityCursorPagination means the server returns coffees in pages
+	// linked by a next_cursor.
+	CapabilityCursorPagination = "cursor_pagination"
+)
+
+// Capabilities -
+type Capabilities struct {
+	Features []string `json:"features"`
+}
+
+// Supports reports whether the server has the feature.
+func (c *Capabilities) Supports(feature string) bool {
+	for _, f := range c.Features {
+		if f == feature {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GetCapabilities - Returns the optional features supported by the server
+func (c *Client) GetCapabilities(ctx context.Context) (*Capabilities, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/capabilities", c.baseURL()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	capabilities := Capabilities{}
+	err = c.decode(body, &capabilities)
+	if err != nil {
+		return nil, err
+	}
+
+	return &capabilities, nil
+}
+
+// supports reports whether the server has the feature, fetching the
+// capabilities once per client. Servers that reject the capabilities request
+// are treated as supporting no optional features.
+func (c *Client) supports(ctx context.Context, feature string) bool {
+	c.capabilitiesMu.Lock()
+	defer c.capabilitiesMu.Unlock()
+
+	if c.capabilities == nil {
+		capabilities, err := c.GetCapabilities(ctx)
+		var apiErr *APIError
+		switch {
+		case err == nil:
+			c.capabilities = capabilities
+		case errors.As(err, &apiErr):
+			c.capabilities = &Capabilities{}
+		default:
+			// Try again on the next request after a transport failure.
+			return false
+		}
+	}
+
+	return c.capabilities.Supports(feature)
+}
@@ -1,10 +1,30 @@
 package hashicups
 
 import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"math/rand"
 	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/sync/singleflight"
+	"golang.org/x/time/rate"
 )
 
 // HostURL - Default Hashicups URL
@@ -12,10 +32,158 @@ const HostURL string = "http://localhost:19090"
 
 // Client -
 type Client struct {
-	HostURL    string
+	HostURL string
+	// APIPath is prepended to the path of every request, for servers routed
+	// under a path prefix such as /hashicups/v1.
+	APIPath    string
 	HTTPClient *http.Client
 	Token      string
 	Auth       AuthStruct
+	// Authenticator adds credentials to each request.
+	Authenticator Authenticator
+	// Credentials supplies the token of each request instead of the
+	// Authenticator. A request rejected with a 401 status is sent once more
+	// with a new token. NewClient sets it to sign in with the username and
+	// password again when the sign-in token expires.
+	Credentials CredentialProvider
+	// DisallowUnknownFields rejects responses containing fields that are not
+	// part of the client models. It is ignored when Codec is set.
+	DisallowUnknownFields bool
+	// Codec encodes and decodes request and response bodies. Nil uses
+	// encoding/json.
+	Codec Codec
+	// RetryMax is the number of times a transient failure is retried.
+	RetryMax int
+	// RetryWaitMin and RetryWaitMax bound the exponential backoff between
+	// retries.
+	RetryWaitMin time.Duration
+	RetryWaitMax time.Duration
+	// RetryMaxElapsedTime stops retrying once the next attempt would start
+	// later than this long after the first. Zero disables the cap.
+	RetryMaxElapsedTime time.Duration
+	// AcceptStatus lists additional status codes treated as success for
+	// servers that respond outside of the 2xx range.
+	AcceptStatus []int
+	// RateLimit caps the requests per second sent to each host. Zero
+	// disables rate limiting.
+	RateLimit float64
+	// MaxConcurrentRequests caps the requests in flight at once across all
+	// operations. Zero disables the limit.
+	MaxConcurrentRequests int
+	// CompressRequests gzip encodes request bodies larger than
+	// compressThreshold when the server supports it.
+	CompressRequests bool
+	// AcceptLanguage is sent as the Accept-Language header so the server can
+	// localize coffee names.
+	AcceptLanguage string
+	// UserAgent is sent as the User-Agent header so server operators can
+	// identify the provider in their access logs.
+	UserAgent string
+	// DeduplicateRequests shares the response of a GET request with identical
+	// GET requests issued while it is in flight.
+	DeduplicateRequests bool
+	// RequestInterceptors run in order on each request after authentication,
+	// just before it is sent. An error aborts the request.
+	RequestInterceptors []func(*http.Request) error
+	// ResponseInterceptors run in order on each response before its body is
+	// read. An error aborts the request.
+	ResponseInterceptors []func(*http.Response) error
+
+	requestGroup singleflight.Group
+
+	limitersMu sync.Mutex
+	limiters   map[string]*rate.Limiter
+
+	semaphoreOnce sync.Once
+	semaphore     chan struct{}
+
+	meMu sync.Mutex
+	me   *User
+
+	credentialsMu    sync.Mutex
+	credentialsToken string
+
+	coffeesCacheMu sync.Mutex
+	coffeesCache   map[string]*coffeesCacheEntry
+
+	capabilitiesMu sync.Mutex
+	capabilities   *Capabilities
+
+	deprecationsMu      sync.Mutex
+	deprecationsSeen    map[string]bool
+	pendingDeprecations []DeprecationNotice
+
+	rateLimitMu     sync.Mutex
+	rateLimitStatus RateLimitStatus
+}
+
+// RateLimitStatus holds the X-RateLimit-Remaining and X-RateLimit-Reset
+// values of the most recent response that sent either header. Fields are nil
+// when the header was missing or not an integer.
+type RateLimitStatus struct {
+	Remaining *int64
+	Reset     *int64
+}
+
+// DeprecationNotice describes an API response carrying a Deprecation or
+// Sunset header.
+type DeprecationNotice struct {
+	Method      string
+	Path        string
+	Deprecation string
+	Sunset      string
+}
+
+// Warning returns a human readable description of the notice.
+func (n DeprecationNotice) Warning() string {
+	warning := fmt.Sprintf("The HashiCups API reports that %s %s is deprecated", n.Method, n.Path)
+	if n.Deprecation != "" {
+		warning += fmt.Sprintf(" (Deprecation: %s)", n.Deprecation)
+	}
+	if n.Sunset != "" {
+		warning += fmt.Sprintf(" and will be removed after %s", n.Sunset)
+	}
+
+	return warning + ". Upgrade the provider or contact the API operator before the endpoint is removed."
+}
+
+// ErrorCodeCoffeeOutOfStock is the API error code returned when an order
+// requests a coffee that is out of stock.
+const ErrorCodeCoffeeOutOfStock = "coffee_out_of_stock"
+
+// APIError is returned when the HashiCups API responds with a non-success
+// status code. Code and Message are set when the body is a JSON error
+// envelope.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+	Code       string
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	if e.Code != "" {
+		return fmt.Sprintf("status: %d, code: %s, message: %s", e.StatusCode, e.Code, e.Message)
+	}
+
+	return fmt.Sprintf("status: %d, body: %s", e.StatusCode, e.Body)
+}
+
+// newAPIError builds an APIError, decoding the {code, message} error
+// envelope when present.
+func newAPIError(statusCode int, body []byte) *APIError {
+	apiErr := &APIError{StatusCode: statusCode, Body: body}
+
+	envelope := struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}{}
+	if json.Unmarshal(body, &envelope) == nil {
+		apiErr.Code = envelope.Code
+		apiErr.Message = envelope.Message
+	}
+
+	return apiErr
 }
 
 // AuthStruct -
@@ -31,51 +199,680 @@ type AuthResponse struct {
 	Token    string `json:"token"`
 }
 
-// NewClient -
+// NewClient - Signs in with the username and password and authenticates
+// requests with the returned token, signing in again when it expires
 func NewClient(host, username, password *string) (*Client, error) {
-	c := Client{
-		HTTPClient: &http.Client{Timeout: 10 * time.Second},
-		// Default Hashicups URL
-		HostURL: HostURL,
-		Auth: AuthStruct{
-			Username: *username,
-			Password: *password,
+	c := newClient(host)
+	if err := c.signInWithPassword(context.Background(), *username, *password, false); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// signInWithPassword signs in with the username and password and
+// authenticates later requests with the returned token, sent with the Bearer
+// scheme when bearer is set. Expired tokens are replaced through
+// PasswordCredentials.
+func (c *Client) signInWithPassword(ctx context.Context, username, password string, bearer bool) error {
+	c.Auth = AuthStruct{
+		Username: username,
+		Password: password,
+	}
+
+	ar, err := c.SignIn(ctx)
+	if err != nil {
+		return err
+	}
+
+	credentials := &PasswordCredentials{Client: c, Username: username, Password: password, Bearer: bearer}
+	c.Token = ar.Token
+	c.Credentials = credentials
+	c.credentialsToken = credentials.header(ar.Token)
+
+	return nil
+}
+
+// NewClientWithAuthenticator - Authenticates requests with auth instead of
+// signing in
+func NewClientWithAuthenticator(host *string, auth Authenticator) *Client {
+	c := newClient(host)
+	c.Authenticator = auth
+
+	return c
+}
+
+// NewClientWithToken - Sends a static API token with the Bearer scheme
+// instead of signing in
+func NewClientWithToken(host *string, token string) *Client {
+	c := newClient(host)
+	c.Token = token
+	c.Authenticator = &BearerAuthenticator{Token: token}
+
+	return c
+}
+
+func newClient(host *string) *Client {
+	c := &Client{
+		HTTPClient: &http.Client{
+			Transport:     defaultTransport(),
+			Timeout:       10 * time.Second,
+			CheckRedirect: checkRedirect,
 		},
+		// Default Hashicups URL
+		HostURL:      HostURL,
+		UserAgent:    userAgent("dev", ""),
+		RetryMax:     3,
+		RetryWaitMin: 1 * time.Second,
+		RetryWaitMax: 30 * time.Second,
 	}
 
 	if host != nil {
 		c.HostURL = *host
 	}
 
-	ar, err := c.SignIn()
-	if err != nil {
-		return nil, err
+	return c
+}
+
+// baseURL returns the URL request paths are appended to: the host URL
+// followed by the APIPath, if any.
+func (c *Client) baseURL() string {
+	prefix := path.Clean("/" + c.APIPath)
+	if prefix == "/" {
+		return c.HostURL
 	}
 
-	c.Token = ar.Token
+	return strings.TrimRight(c.HostURL, "/") + prefix
+}
+
+// userAgent returns the User-Agent of the provider version, followed by
+// suffix when it is set.
+func userAgent(version, suffix string) string {
+	agent := fmt.Sprintf("terraform-provider-hashicups/%s (terraform-plugin-framework)", version)
+	if suffix != "" {
+		agent += " " + suffix
+	}
+
+	return agent
+}
+
+// defaultTransport returns a copy of the default transport, sending requests
+// through the proxy named by the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY
+// environment variables.
+func defaultTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = http.ProxyFromEnvironment
+
+	return transport
+}
+
+// SetProxyURL sends all requests through the HTTP, HTTPS, or SOCKS5 proxy at
+// proxyURL, ignoring the proxy environment variables.
+func (c *Client) SetProxyURL(proxyURL *url.URL) {
+	c.transport().Proxy = http.ProxyURL(proxyURL)
+}
+
+// SetRootCAs makes the client trust only the certificate authorities in
+// pool when verifying the server, for servers behind a TLS proxy with a
+// private CA.
+func (c *Client) SetRootCAs(pool *x509.CertPool) {
+	c.tlsConfig().RootCAs = pool
+}
+
+// SetInsecureSkipVerify turns off verification of the server certificate,
+// for development servers with self-signed certificates. Any root CAs set
+// with SetRootCAs are kept but have no effect while verification is off.
+func (c *Client) SetInsecureSkipVerify(skip bool) {
+	c.tlsConfig().InsecureSkipVerify = skip
+}
+
+// tlsConfig returns the TLS configuration of the client transport.
+func (c *Client) tlsConfig() *tls.Config {
+	transport := c.transport()
+	if transport.TLSClientConfig == nil {
+		transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+	}
+
+	return transport.TLSClientConfig
+}
+
+// transport replaces the client transport with a clone, so a transport
+// shared with other clients is never modified, and returns it.
+func (c *Client) transport() *http.Transport {
+	transport, ok := c.HTTPClient.Transport.(*http.Transport)
+	if !ok {
+		transport = defaultTransport()
+	}
+	transport = transport.Clone()
+
+	c.HTTPClient.Transport = transport
+	return transport
+}
+
+// maxRedirects is the number of redirects followed before a request fails.
+const maxRedirects = 10
+
+// credentialHeadersKey holds the names of the headers authentication set on
+// a request.
+type credentialHeadersKey struct{}
+
+// withCredentialHeaders returns req recording the headers that differ from
+// unauthenticated, the headers of req before authentication was applied.
+func withCredentialHeaders(req *http.Request, unauthenticated http.Header) *http.Request {
+	var headers []string
+	for name, values := range req.Header {
+		if !slices.Equal(values, unauthenticated[name]) {
+			headers = append(headers, name)
+		}
+	}
+	if len(headers) == 0 {
+		return req
+	}
+
+	return req.WithContext(context.WithValue(req.Context(), credentialHeadersKey{}, headers))
+}
 
-	return &c, nil
+// checkRedirect follows redirects of GET and HEAD requests only, so writes
+// are never silently replayed against another URL. Credentials, including
+// custom headers such as an API key, are removed when a redirect leaves the
+// original host.
+func checkRedirect(req *http.Request, via []*http.Request) error {
+	if len(via) >= maxRedirects {
+		return fmt.Errorf("stopped after %d redirects", maxRedirects)
+	}
+
+	original := via[0]
+	if original.Method != http.MethodGet && original.Method != http.MethodHead {
+		return http.ErrUseLastResponse
+	}
+
+	if req.URL.Host != original.URL.Host {
+		req.Header.Del("Authorization")
+		headers, _ := original.Context().Value(credentialHeadersKey{}).([]string)
+		for _, header := range headers {
+			req.Header.Del(header)
+		}
+	}
+
+	return nil
 }
 
 func (c *Client) doRequest(req *http.Request) ([]byte, error) {
-	req.Header.Set("Authorization", c.Token)
+	body, err := c.sendRequest(req)
+
+	var apiErr *APIError
+	if c.Credentials == nil || !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized ||
+		req.Context().Value(withoutCredentialsKey{}) != nil {
+		return body, err
+	}
+
+	// The token may have expired, so send the request again with a new one.
+	if _, err := c.credentialToken(req.Context(), req.Header.Get("Authorization")); err != nil {
+		return nil, err
+	}
+	if req.GetBody != nil {
+		reqBody, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = reqBody
+	}
+
+	return c.sendRequest(req)
+}
+
+// sendRequest authenticates and sends req once, returning the response body
+// or an *APIError for an unsuccessful status.
+func (c *Client) sendRequest(req *http.Request) ([]byte, error) {
+	if c.CompressRequests {
+		err := c.compressBody(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.AcceptLanguage != "" {
+		req.Header.Set("Accept-Language", c.AcceptLanguage)
+	}
+	if c.UserAgent != "" {
+		req.Header.Set("User-Agent", c.UserAgent)
+	}
+
+	unauthenticated := req.Header.Clone()
+	switch {
+	case req.Context().Value(withoutCredentialsKey{}) != nil:
+	case c.Credentials != nil:
+		err := c.applyCredentials(req)
+		if err != nil {
+			return nil, err
+		}
+	case c.Authenticator != nil:
+		err := c.Authenticator.Apply(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+	req = withCredentialHeaders(req, unauthenticated)
+
+	for _, intercept := range c.RequestInterceptors {
+		err := intercept(req)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.RateLimit > 0 {
+		err := c.limiter(req.URL.Host).Wait(req.Context())
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if c.MaxConcurrentRequests > 0 {
+		release, err := c.acquire(req.Context())
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	ctx := maskRequestLogs(req.Context())
+	fields := map[string]any{
+		"method": req.Method,
+		"url":    req.URL.Redacted(),
+	}
+	if req.GetBody != nil && req.Header.Get("Content-Encoding") == "" {
+		if reqBody, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(reqBody)
+			tflog.Trace(ctx, "Sending HashiCups API request body", fields, map[string]any{"body": string(data)})
+		}
+	}
 
+	start := time.Now()
 	res, err := c.HTTPClient.Do(req)
+	fields["duration_ms"] = time.Since(start).Milliseconds()
 	if err != nil {
+		fields["error"] = err.Error()
+		tflog.Debug(ctx, "HashiCups API request failed", fields)
 		return nil, err
 	}
 	defer func(Body io.ReadCloser) {
 		_ = Body.Close()
 	}(res.Body)
+	fields["status_code"] = res.StatusCode
+	tflog.Debug(ctx, "HashiCups API request", fields)
+
+	for _, intercept := range c.ResponseInterceptors {
+		err := intercept(res)
+		if err != nil {
+			return nil, err
+		}
+	}
 
 	body, err := io.ReadAll(res.Body)
 	if err != nil {
 		return nil, err
 	}
+	tflog.Trace(ctx, "Received HashiCups API response body", fields, map[string]any{"body": string(body)})
+
+	c.recordDeprecation(req, res)
+	c.recordRateLimit(res)
 
-	if res.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("status: %d, body: %s", res.StatusCode, body)
+	if !c.isSuccess(res.StatusCode) {
+		return nil, newAPIError(res.StatusCode, body)
 	}
 
 	return body, err
 }
+
+// secretBodyPattern matches JSON fields holding credentials, such as the
+// password sent to sign in and the token it returns.
+var secretBodyPattern = regexp.MustCompile(`"(?i:password|token)"\s*:\s*"[^"]*"`)
+
+// maskRequestLogs hides credentials from the request logs of ctx. Bodies are
+// only logged at the trace level.
+func maskRequestLogs(ctx context.Context) context.Context {
+	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "password", "token")
+	return tflog.MaskAllFieldValuesRegexes(ctx, secretBodyPattern)
+}
+
+// recordDeprecation queues a notice when res carries a Deprecation or Sunset
+// header. Each distinct pair of header values is queued once per client.
+func (c *Client) recordDeprecation(req *http.Request, res *http.Response) {
+	notice := DeprecationNotice{
+		Method:      req.Method,
+		Path:        req.URL.Path,
+		Deprecation: res.Header.Get("Deprecation"),
+		Sunset:      res.Header.Get("Sunset"),
+	}
+	if notice.Deprecation == "" && notice.Sunset == "" {
+		return
+	}
+
+	c.deprecationsMu.Lock()
+	defer c.deprecationsMu.Unlock()
+
+	key := notice.Deprecation + "|" + notice.Sunset
+	if c.deprecationsSeen[key] {
+		return
+	}
+	if c.deprecationsSeen == nil {
+		c.deprecationsSeen = make(map[string]bool)
+	}
+	c.deprecationsSeen[key] = true
+	c.pendingDeprecations = append(c.pendingDeprecations, notice)
+}
+
+// DeprecationNotices returns the deprecation notices received since the last
+// call.
+func (c *Client) DeprecationNotices() []DeprecationNotice {
+	c.deprecationsMu.Lock()
+	defer c.deprecationsMu.Unlock()
+
+	notices := c.pendingDeprecations
+	c.pendingDeprecations = nil
+
+	return notices
+}
+
+// recordRateLimit keeps the rate limit headers of res. Responses without
+// either header leave the previous values in place.
+func (c *Client) recordRateLimit(res *http.Response) {
+	remaining := res.Header.Get("X-RateLimit-Remaining")
+	reset := res.Header.Get("X-RateLimit-Reset")
+	if remaining == "" && reset == "" {
+		return
+	}
+
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+
+	c.rateLimitStatus = RateLimitStatus{
+		Remaining: parseRateLimitHeader(remaining),
+		Reset:     parseRateLimitHeader(reset),
+	}
+}
+
+// parseRateLimitHeader returns the integer value of a rate limit header, or
+// nil when it is missing or malformed.
+func parseRateLimitHeader(value string) *int64 {
+	n, err := strconv.ParseInt(strings.TrimSpace(value), 10, 64)
+	if err != nil {
+		return nil
+	}
+
+	return &n
+}
+
+// LastRateLimit returns the rate limit headers of the most recent response that
+// sent them.
+func (c *Client) LastRateLimit() RateLimitStatus {
+	c.rateLimitMu.Lock()
+	defer c.rateLimitMu.Unlock()
+
+	return c.rateLimitStatus
+}
+
+// compressThreshold is the request body size in bytes above which bodies are
+// compressed.
+const compressThreshold = 1024
+
+// compressBody gzip encodes the body of req when it is larger than
+// compressThreshold and the server accepts gzip encoded requests.
+func (c *Client) compressBody(req *http.Request) error {
+	if req.Body == nil || req.Body == http.NoBody || req.Header.Get("Content-Encoding") != "" {
+		return nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return err
+	}
+	_ = req.Body.Close()
+
+	if len(body) > compressThreshold && c.supports(req.Context(), CapabilityGzipRequests) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(body); err != nil {
+			return err
+		}
+		if err := zw.Close(); err != nil {
+			return err
+		}
+
+		body = buf.Bytes()
+		req.Header.Set("Content-Encoding", "gzip")
+	}
+
+	req.ContentLength = int64(len(body))
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	return nil
+}
+
+// isSuccess reports whether statusCode is in the 2xx range or configured in
+// AcceptStatus.
+func (c *Client) isSuccess(statusCode int) bool {
+	if statusCode >= 200 && statusCode < 300 {
+		return true
+	}
+
+	for _, accepted := range c.AcceptStatus {
+		if statusCode == accepted {
+			return true
+		}
+	}
+
+	return false
+}
+
+// limiter returns the rate limiter for host, creating it on first use so each
+// host has an independent budget.
+func (c *Client) limiter(host string) *rate.Limiter {
+	c.limitersMu.Lock()
+	defer c.limitersMu.Unlock()
+
+	if c.limiters == nil {
+		c.limiters = make(map[string]*rate.Limiter)
+	}
+
+	l, ok := c.limiters[host]
+	if !ok {
+		l = rate.NewLimiter(rate.Limit(c.RateLimit), 1)
+		c.limiters[host] = l
+	}
+
+	return l
+}
+
+// acquire blocks until fewer than MaxConcurrentRequests requests are in
+// flight or ctx is done. The returned function releases the slot.
+func (c *Client) acquire(ctx context.Context) (func(), error) {
+	c.semaphoreOnce.Do(func() {
+		c.semaphore = make(chan struct{}, c.MaxConcurrentRequests)
+	})
+
+	select {
+	case c.semaphore <- struct{}{}:
+		return func() { <-c.semaphore }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// retryError wraps the final error of a request that was attempted more than
+// once.
+type retryError struct {
+	attempts int
+	err      error
+}
+
+func (e *retryError) Error() string {
+	return fmt.Sprintf("giving up after %d attempts: %s", e.attempts, e.err)
+}
+
+func (e *retryError) Unwrap() error {
+	return e.err
+}
+
+// doRequestWithRetry sends req with retries. With DeduplicateRequests,
+// identical GET requests in flight at once share a single round trip. The
+// shared round trip ignores the cancellation of whichever caller started it,
+// so that caller giving up does not fail the others; each caller still
+// returns as soon as its own ctx is done.
+func (c *Client) doRequestWithRetry(ctx context.Context, req *http.Request) ([]byte, error) {
+	if !c.DeduplicateRequests || req.Method != http.MethodGet {
+		return c.retryRequest(ctx, req)
+	}
+
+	result := c.requestGroup.DoChan(req.Method+" "+req.URL.String(), func() (any, error) {
+		return c.retryRequest(context.WithoutCancel(ctx), req)
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case res := <-result:
+		if res.Err != nil {
+			return nil, res.Err
+		}
+		return res.Val.([]byte), nil
+	}
+}
+
+// retryPolicy adjusts how retryRequestWithPolicy retries a single request.
+type retryPolicy struct {
+	// retryAnyMethod retries POST and PUT requests that are safe to repeat,
+	// such as signing in, without an idempotency key.
+	retryAnyMethod bool
+	// maxRetries, when positive, lowers RetryMax for the request.
+	maxRetries int
+}
+
+// retryRequest sends req, retrying transient failures with exponential
+// backoff until RetryMax retries are exhausted or ctx is done.
+func (c *Client) retryRequest(ctx context.Context, req *http.Request) ([]byte, error) {
+	return c.retryRequestWithPolicy(ctx, req, retryPolicy{})
+}
+
+// retryRequestWithPolicy is retryRequest with the retries adjusted by policy.
+func (c *Client) retryRequestWithPolicy(ctx context.Context, req *http.Request, policy retryPolicy) ([]byte, error) {
+	retryMax := c.RetryMax
+	if policy.maxRetries > 0 && policy.maxRetries < retryMax {
+		retryMax = policy.maxRetries
+	}
+
+	req = req.WithContext(ctx)
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		if attempt > 0 && req.GetBody != nil {
+			reqBody, err := req.GetBody()
+			if err != nil {
+				return nil, err
+			}
+			req.Body = reqBody
+		}
+
+		body, err := c.doRequest(req)
+		if err == nil {
+			return body, nil
+		}
+		wait := c.backoff(attempt)
+		elapsedExceeded := c.RetryMaxElapsedTime > 0 && time.Since(start)+wait > c.RetryMaxElapsedTime
+		retryable := (policy.retryAnyMethod || isRetryableMethod(req, req.Header.Get(idempotencyKeyHeader) != "")) && isRetryableError(err)
+		if attempt >= retryMax || elapsedExceeded || !retryable {
+			if attempt > 0 {
+				err = &retryError{attempts: attempt + 1, err: err}
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// idempotencyKeyHeader carries a client-chosen key that lets the server
+// recognise a repeated POST or PUT and apply it only once.
+const idempotencyKeyHeader = "Idempotency-Key"
+
+// isRetryableMethod reports whether req may be sent again after a failure.
+// GET and DELETE are idempotent; POST and PUT are retried only with an
+// idempotency key, so a retry cannot place a duplicate order.
+func isRetryableMethod(req *http.Request, hasIdempotencyKey bool) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodDelete:
+		return true
+	case http.MethodPost, http.MethodPut:
+		return hasIdempotencyKey
+	}
+
+	return false
+}
+
+// backoff returns the wait before the given retry attempt.
+func (c *Client) backoff(attempt int) time.Duration {
+	wait := c.RetryWaitMin << attempt
+	if wait <= 0 || (c.RetryWaitMax > 0 && wait > c.RetryWaitMax) {
+		wait = c.RetryWaitMax
+	}
+
+	// Wait a random duration in the upper half of the backoff so clients
+	// failing together do not retry in lockstep.
+	if half := wait / 2; half > 0 {
+		wait = half + time.Duration(rand.Int63n(int64(half)+1))
+	}
+
+	return wait
+}
+
+// isRetryableError reports whether err is a transient API failure or a
+// refused connection, as seen while a server restarts.
+func isRetryableError(err error) bool {
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return true
+	}
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.StatusCode {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+
+	return false
+}
+
+// encode marshals a request body using the configured Codec.
+func (c *Client) encode(v any) ([]byte, error) {
+	if c.Codec != nil {
+		return c.Codec.Marshal(v)
+	}
+
+	return json.Marshal(v)
+}
+
+// decode unmarshals a response body into v using the configured Codec,
+// honouring DisallowUnknownFields with the default codec.
+func (c *Client) decode(body []byte, v any) error {
+	if c.Codec != nil {
+		return c.Codec.Unmarshal(body, v)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(body))
+	if c.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	return dec.Decode(v)
+}
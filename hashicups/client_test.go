@@ -0,0 +1,1208 @@
+package hashicups
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
+)
+
+// newTestClient returns a Client pointed at a test server running handler.
+// The client assumes the server supports no optional features; tests of
+// capabilities reset the capabilities field to query the server.
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return &Client{
+		HostURL:      server.URL,
+		HTTPClient:   server.Client(),
+		capabilities: &Capabilities{},
+	}
+}
+
+// newTestCRUDHandler returns a handler serving an in-memory collection of T
+// under prefix, like the HashiCups create, read, update, and delete
+// endpoints. POST stores the next ID with setID and DELETE responds with
+// deleted.
+func newTestCRUDHandler[T any](prefix, deleted string, setID func(*T, int)) http.HandlerFunc {
+	var mu sync.Mutex
+	items := map[string]T{}
+	nextID := 1
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		id := strings.TrimPrefix(strings.TrimPrefix(r.URL.Path, prefix), "/")
+		switch r.Method {
+		case "POST", "PUT":
+			var item T
+			if err := json.NewDecoder(r.Body).Decode(&item); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if r.Method == "POST" {
+				id = strconv.Itoa(nextID)
+				nextID++
+			}
+			itemID, _ := strconv.Atoi(id)
+			setID(&item, itemID)
+			items[id] = item
+			_ = json.NewEncoder(w).Encode(item)
+		case "GET":
+			item, ok := items[id]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(item)
+		case "DELETE":
+			delete(items, id)
+			_, _ = w.Write([]byte(deleted))
+		}
+	}
+}
+
+func TestClientDisallowUnknownFields(t *testing.T) {
+	tests := map[string]struct {
+		body                  string
+		disallowUnknownFields bool
+		expectError           bool
+	}{
+		"clean payload strict": {
+			body:                  `[{"id":1,"name":"HCP Aeropress","ingredients":[{"ingredient_id":6}]}]`,
+			disallowUnknownFields: true,
+		},
+		"extra field lenient": {
+			body: `[{"id":1,"name":"HCP Aeropress","collection":"Origins"}]`,
+		},
+		"extra field strict": {
+			body:                  `[{"id":1,"name":"HCP Aeropress","collection":"Origins"}]`,
+			disallowUnknownFields: true,
+			expectError:           true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				_, _ = w.Write([]byte(test.body))
+			})
+			client.DisallowUnknownFields = test.disallowUnknownFields
+
+			coffees, err := client.GetCoffees(context.Background())
+			if test.expectError {
+				if err == nil {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if len(coffees) != 1 || coffees[0].Name != "HCP Aeropress" {
+				t.Errorf("unexpected coffees: %+v", coffees)
+			}
+		})
+	}
+}
+
+func TestClientReadsRetryTransientErrors(t *testing.T) {
+	tests := map[string]struct {
+		body string
+		read func(*Client) error
+	}{
+		"order": {
+			body: `{"id":7}`,
+			read: func(c *Client) error {
+				_, err := c.GetOrder(context.Background(), "7")
+				return err
+			},
+		},
+		"coffee ingredients": {
+			body: `[{"ingredient_id":1}]`,
+			read: func(c *Client) error {
+				_, err := c.GetCoffeeIngredients(context.Background(), "1")
+				return err
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if atomic.AddInt32(&attempts, 1) == 1 {
+					w.WriteHeader(http.StatusBadGateway)
+					return
+				}
+				_, _ = w.Write([]byte(test.body))
+			})
+			client.RetryMax = 2
+			client.RetryWaitMin = time.Millisecond
+			client.RetryWaitMax = time.Millisecond
+
+			if err := test.read(client); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if attempts != 2 {
+				t.Errorf("expected 2 attempts, got %d", attempts)
+			}
+		})
+	}
+}
+
+func TestClientRetryRespectsContext(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.RetryMax = 10
+	client.RetryWaitMin = time.Hour
+	client.RetryWaitMax = time.Hour
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	if _, err := client.GetCoffees(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context deadline error, got %v", err)
+	}
+}
+
+func TestClientRateLimitPerHost(t *testing.T) {
+	handler := func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}
+	client := newTestClient(t, handler)
+	client.RateLimit = 1
+
+	other := httptest.NewServer(http.HandlerFunc(handler))
+	defer other.Close()
+
+	get := func(host string, timeout time.Duration) error {
+		ctx, cancel := context.WithTimeout(context.Background(), timeout)
+		defer cancel()
+
+		req, err := http.NewRequestWithContext(ctx, "GET", host+"/coffees", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = client.doRequest(req)
+
+		return err
+	}
+
+	if err := get(client.HostURL, time.Second); err != nil {
+		t.Fatalf("first request to host A: %s", err)
+	}
+	// Host A's budget is exhausted for the next second.
+	if err := get(client.HostURL, 100*time.Millisecond); err == nil {
+		t.Error("expected second request to host A to be throttled")
+	}
+	// Host B has an independent budget.
+	if err := get(other.URL, 100*time.Millisecond); err != nil {
+		t.Errorf("request to host B was throttled: %s", err)
+	}
+}
+
+func TestClientSuccessStatus(t *testing.T) {
+	tests := map[string]struct {
+		status       int
+		acceptStatus []int
+		expectError  bool
+	}{
+		"201":                   {status: http.StatusCreated},
+		"202":                   {status: http.StatusAccepted},
+		"204":                   {status: http.StatusNoContent},
+		"304 not accepted":      {status: http.StatusNotModified, expectError: true},
+		"304 accepted":          {status: http.StatusNotModified, acceptStatus: []int{http.StatusNotModified}},
+		"400 with 304 accepted": {status: http.StatusBadRequest, acceptStatus: []int{http.StatusNotModified}, expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.status)
+			})
+			client.AcceptStatus = test.acceptStatus
+
+			req, err := http.NewRequest("DELETE", client.HostURL+"/orders/1", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = client.doRequest(req)
+			if test.expectError && err == nil {
+				t.Error("expected error, got none")
+			}
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestClientEmptyResponses(t *testing.T) {
+	tests := map[string]int{
+		"200 empty body": http.StatusOK,
+		"204 no content": http.StatusNoContent,
+	}
+
+	for name, status := range tests {
+		t.Run(name, func(t *testing.T) {
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(status)
+			})
+
+			if err := client.DeleteOrder(context.Background(), "1"); err != nil {
+				t.Errorf("delete order: %s", err)
+			}
+			if err := client.DeleteCombo(context.Background(), "1"); err != nil {
+				t.Errorf("delete combo: %s", err)
+			}
+			if err := client.SignOut(context.Background()); err != nil {
+				t.Errorf("sign out: %s", err)
+			}
+		})
+	}
+}
+
+func TestClientDeleteOrderIdempotentRetry(t *testing.T) {
+	tests := map[string]struct {
+		statuses    []int
+		expectError bool
+	}{
+		"retried delete already applied": {
+			statuses: []int{http.StatusServiceUnavailable, http.StatusNotFound},
+		},
+		"missing order": {
+			statuses:    []int{http.StatusNotFound},
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				call := int(atomic.AddInt32(&calls, 1)) - 1
+				if call < len(test.statuses) {
+					w.WriteHeader(test.statuses[call])
+					return
+				}
+				_, _ = w.Write([]byte("Deleted order"))
+			})
+			client.RetryMax = 3
+			client.RetryWaitMin = time.Millisecond
+			client.RetryWaitMax = time.Millisecond
+
+			err := client.DeleteOrder(context.Background(), "1")
+			if test.expectError && err == nil {
+				t.Error("expected error, got none")
+			}
+			if !test.expectError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestClientErrorEnvelope(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"code":"coffee_out_of_stock","message":"Packer Spiced Latte is out of stock"}`))
+	})
+
+	_, err := client.CreateOrder(context.Background(), []OrderItem{{Coffee: Coffee{ID: 2}, Quantity: 1}})
+
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("expected APIError, got %v", err)
+	}
+	if apiErr.StatusCode != http.StatusConflict || apiErr.Code != ErrorCodeCoffeeOutOfStock || apiErr.Message != "Packer Spiced Latte is out of stock" {
+		t.Errorf("unexpected APIError: %+v", apiErr)
+	}
+}
+
+func TestClientAuthSchemes(t *testing.T) {
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/signin" {
+			_, _ = w.Write([]byte(`{"user_id":1,"username":"education","token":"signed-in-token"}`))
+			return
+		}
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	defer server.Close()
+
+	tests := map[string]func(t *testing.T, h http.Header){
+		authSchemeToken: func(t *testing.T, h http.Header) {
+			if got := h.Get("Authorization"); got != "signed-in-token" {
+				t.Errorf("unexpected Authorization header %q", got)
+			}
+		},
+		authSchemeBearer: func(t *testing.T, h http.Header) {
+			if got := h.Get("Authorization"); got != "Bearer signed-in-token" {
+				t.Errorf("unexpected Authorization header %q", got)
+			}
+		},
+		authSchemeBasic: func(t *testing.T, h http.Header) {
+			req := &http.Request{Header: h}
+			username, password, ok := req.BasicAuth()
+			if !ok || username != "education" || password != "test123" {
+				t.Errorf("unexpected basic auth %q:%q", username, password)
+			}
+		},
+		authSchemeHMAC: func(t *testing.T, h http.Header) {
+			date := h.Get("X-HashiCups-Date")
+			if date == "" {
+				t.Fatal("missing X-HashiCups-Date header")
+			}
+			expected := "HMAC-SHA256 Credential=education, Signature=" + hmacSignature("test123", "GET", "/coffees", date)
+			if got := h.Get("Authorization"); got != expected {
+				t.Errorf("expected Authorization header %q, got %q", expected, got)
+			}
+		},
+	}
+
+	for scheme, check := range tests {
+		t.Run(scheme, func(t *testing.T) {
+			client, err := newAuthenticatedClient(context.Background(), scheme, server.URL, "education", "test123", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, err := client.GetCoffees(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			check(t, header)
+		})
+	}
+}
+
+func TestClientRedirects(t *testing.T) {
+	var crossHostAuth, crossHostKey string
+	other := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		crossHostAuth = r.Header.Get("Authorization")
+		crossHostKey = r.Header.Get("X-Gateway-Key")
+		_, _ = w.Write([]byte(`[{"id":2}]`))
+	}))
+	defer other.Close()
+
+	var sameHostAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees":
+			http.Redirect(w, r, "/v2/coffees", http.StatusMovedPermanently)
+		case "/v2/coffees":
+			sameHostAuth = r.Header.Get("Authorization")
+			_, _ = w.Write([]byte(`[{"id":1}]`))
+		case "/orders":
+			http.Redirect(w, r, other.URL+"/orders", http.StatusTemporaryRedirect)
+		default:
+			http.Redirect(w, r, other.URL+"/coffees", http.StatusFound)
+		}
+	}))
+	defer server.Close()
+
+	client := NewClientWithAuthenticator(&server.URL, &BearerAuthenticator{Token: "secret"})
+
+	t.Run("same host", func(t *testing.T) {
+		coffees, err := client.GetCoffees(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(coffees) != 1 || coffees[0].ID != 1 {
+			t.Errorf("unexpected coffees: %+v", coffees)
+		}
+		if sameHostAuth != "Bearer secret" {
+			t.Errorf("expected Authorization header to be kept, got %q", sameHostAuth)
+		}
+	})
+
+	t.Run("cross host", func(t *testing.T) {
+		req, err := http.NewRequest("GET", server.URL+"/moved", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.doRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		if crossHostAuth != "" {
+			t.Errorf("expected Authorization header to be stripped, got %q", crossHostAuth)
+		}
+	})
+
+	t.Run("cross host api key", func(t *testing.T) {
+		client := NewClientWithAuthenticator(&server.URL, &APIKeyAuthenticator{Header: "X-Gateway-Key", Key: "secret"})
+
+		req, err := http.NewRequest("GET", server.URL+"/moved", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := client.doRequest(req); err != nil {
+			t.Fatal(err)
+		}
+		if crossHostKey != "" {
+			t.Errorf("expected X-Gateway-Key header to be stripped, got %q", crossHostKey)
+		}
+	})
+
+	t.Run("write not followed", func(t *testing.T) {
+		_, err := client.CreateOrder(context.Background(), []OrderItem{{Coffee: Coffee{ID: 1}, Quantity: 1}})
+
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTemporaryRedirect {
+			t.Errorf("expected redirect status error, got %v", err)
+		}
+	})
+}
+
+func TestClientMaxConcurrentRequests(t *testing.T) {
+	var inFlight, maxInFlight int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		current := atomic.AddInt32(&inFlight, 1)
+		defer atomic.AddInt32(&inFlight, -1)
+		for {
+			seen := atomic.LoadInt32(&maxInFlight)
+			if current <= seen || atomic.CompareAndSwapInt32(&maxInFlight, seen, current) {
+				break
+			}
+		}
+		time.Sleep(20 * time.Millisecond)
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.MaxConcurrentRequests = 2
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := client.GetCoffees(context.Background()); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := atomic.LoadInt32(&maxInFlight); got > 2 {
+		t.Errorf("expected at most 2 requests in flight, got %d", got)
+	}
+}
+
+func TestClientDeduplicateRequests(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+	})
+	client.DeduplicateRequests = true
+
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			coffees, err := client.GetCoffees(context.Background())
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(coffees) != 1 || coffees[0].Name != "HCP Aeropress" {
+				t.Errorf("unexpected coffees: %+v", coffees)
+			}
+		}()
+	}
+
+	// Give every caller time to join the request in flight.
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestClientDeduplicateRequestsCancel(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		<-release
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+	})
+	client.DeduplicateRequests = true
+
+	// The first caller starts the shared request, then gives up.
+	ctx, cancel := context.WithCancel(context.Background())
+	firstDone := make(chan error, 1)
+	go func() {
+		_, err := client.GetCoffees(ctx)
+		firstDone <- err
+	}()
+	for atomic.LoadInt32(&calls) == 0 {
+		time.Sleep(time.Millisecond)
+	}
+
+	secondDone := make(chan error, 1)
+	go func() {
+		coffees, err := client.GetCoffees(context.Background())
+		if err == nil && len(coffees) != 1 {
+			err = errors.New("unexpected coffees")
+		}
+		secondDone <- err
+	}()
+
+	// Give the second caller time to join the request in flight.
+	time.Sleep(50 * time.Millisecond)
+	cancel()
+	select {
+	case err := <-firstDone:
+		if !errors.Is(err, context.Canceled) {
+			t.Errorf("expected the canceled caller to return context.Canceled, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("canceled caller did not return while the shared request was in flight")
+	}
+
+	close(release)
+	if err := <-secondDone; err != nil {
+		t.Errorf("expected the other caller to succeed, got %v", err)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 1 {
+		t.Errorf("expected 1 request, got %d", calls)
+	}
+}
+
+func TestClientCompressRequests(t *testing.T) {
+	tests := map[string]struct {
+		items        int
+		capabilities string
+		expectGzip   bool
+	}{
+		"small body":        {items: 1, capabilities: `{"features":["gzip_requests"]}`},
+		"large body":        {items: 100, capabilities: `{"features":["gzip_requests"]}`, expectGzip: true},
+		"large unsupported": {items: 100, capabilities: `{"features":[]}`},
+		"large no endpoint": {items: 100},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var encoding string
+			var items []OrderItem
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/capabilities" {
+					if test.capabilities == "" {
+						w.WriteHeader(http.StatusNotFound)
+						return
+					}
+					_, _ = w.Write([]byte(test.capabilities))
+					return
+				}
+
+				encoding = r.Header.Get("Content-Encoding")
+				body := io.Reader(r.Body)
+				if encoding == "gzip" {
+					zr, err := gzip.NewReader(r.Body)
+					if err != nil {
+						t.Errorf("invalid gzip body: %s", err)
+						return
+					}
+					body = zr
+				}
+				if err := json.NewDecoder(body).Decode(&items); err != nil {
+					t.Errorf("invalid body: %s", err)
+				}
+				_, _ = w.Write([]byte(`{"id":1}`))
+			})
+			client.capabilities = nil
+			client.CompressRequests = true
+
+			var order []OrderItem
+			for i := 0; i < test.items; i++ {
+				order = append(order, OrderItem{Coffee: Coffee{ID: i}, Quantity: 1})
+			}
+			if _, err := client.CreateOrder(context.Background(), order); err != nil {
+				t.Fatal(err)
+			}
+
+			if got := encoding == "gzip"; got != test.expectGzip {
+				t.Errorf("expected gzip %t, got Content-Encoding %q", test.expectGzip, encoding)
+			}
+			if len(items) != test.items {
+				t.Errorf("expected %d items to be received, got %d", test.items, len(items))
+			}
+		})
+	}
+}
+
+func TestClientRetryMaxElapsedTime(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	})
+	client.RetryMax = 1000
+	client.RetryWaitMin = 10 * time.Millisecond
+	client.RetryWaitMax = 20 * time.Millisecond
+	client.RetryMaxElapsedTime = 200 * time.Millisecond
+
+	start := time.Now()
+	_, err := client.GetCoffees(context.Background())
+	elapsed := time.Since(start)
+
+	var retryErr *retryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected retry error, got %v", err)
+	}
+	if elapsed > client.RetryMaxElapsedTime+100*time.Millisecond {
+		t.Errorf("expected to give up after about %s, took %s", client.RetryMaxElapsedTime, elapsed)
+	}
+	if elapsed < client.RetryMaxElapsedTime-client.RetryWaitMax {
+		t.Errorf("gave up after %s, before the %s cap", elapsed, client.RetryMaxElapsedTime)
+	}
+	if atomic.LoadInt32(&calls) < 3 {
+		t.Errorf("expected several attempts, got %d", calls)
+	}
+}
+
+func TestClientRetryServerErrors(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.RetryMax = 3
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = time.Millisecond
+
+	if _, err := client.GetCoffees(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if calls != 2 {
+		t.Errorf("expected 2 attempts, got %d", calls)
+	}
+}
+
+func TestClientRetryConnectionRefused(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+
+	client := &Client{
+		HostURL:      server.URL,
+		HTTPClient:   http.DefaultClient,
+		RetryMax:     2,
+		RetryWaitMin: time.Millisecond,
+		RetryWaitMax: time.Millisecond,
+		capabilities: &Capabilities{},
+	}
+
+	_, err := client.GetCoffees(context.Background())
+	var retryErr *retryError
+	if !errors.As(err, &retryErr) {
+		t.Fatalf("expected retry error, got %v", err)
+	}
+	if retryErr.attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", retryErr.attempts)
+	}
+}
+
+func TestClientRetryIdempotentMethods(t *testing.T) {
+	tests := map[string]struct {
+		method         string
+		idempotencyKey string
+		expectCalls    int32
+	}{
+		"get":              {method: http.MethodGet, expectCalls: 3},
+		"delete":           {method: http.MethodDelete, expectCalls: 3},
+		"post without key": {method: http.MethodPost, expectCalls: 1},
+		"post with key":    {method: http.MethodPost, idempotencyKey: "order-1", expectCalls: 3},
+		"put without key":  {method: http.MethodPut, expectCalls: 1},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				w.WriteHeader(http.StatusServiceUnavailable)
+			})
+			client.RetryMax = 2
+			client.RetryWaitMin = time.Millisecond
+			client.RetryWaitMax = time.Millisecond
+
+			req, err := http.NewRequest(test.method, client.HostURL+"/orders", strings.NewReader(`[]`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.idempotencyKey != "" {
+				req.Header.Set(idempotencyKeyHeader, test.idempotencyKey)
+			}
+
+			if _, err := client.doRequestWithRetry(context.Background(), req); err == nil {
+				t.Fatal("expected error, got none")
+			}
+			if calls != test.expectCalls {
+				t.Errorf("expected %d attempts, got %d", test.expectCalls, calls)
+			}
+		})
+	}
+}
+
+func TestClientBackoffJitter(t *testing.T) {
+	client := &Client{RetryWaitMin: 100 * time.Millisecond, RetryWaitMax: time.Second}
+
+	for attempt, expect := range []time.Duration{100 * time.Millisecond, 200 * time.Millisecond, 400 * time.Millisecond, 800 * time.Millisecond, time.Second} {
+		for i := 0; i < 20; i++ {
+			if wait := client.backoff(attempt); wait < expect/2 || wait > expect {
+				t.Fatalf("attempt %d: expected a wait between %s and %s, got %s", attempt, expect/2, expect, wait)
+			}
+		}
+	}
+}
+
+func TestClientInterceptors(t *testing.T) {
+	var requests int32
+	var traceID string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		traceID = r.Header.Get("X-Trace-ID")
+		w.Header().Set("X-Served-By", "hashicups-1")
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+	})
+
+	var calls []string
+	var servedBy string
+	client.RequestInterceptors = []func(*http.Request) error{
+		func(req *http.Request) error {
+			calls = append(calls, "request 1")
+			req.Header.Set("X-Trace-ID", "trace-1")
+			return nil
+		},
+		func(req *http.Request) error {
+			calls = append(calls, "request 2: "+req.Header.Get("X-Trace-ID"))
+			return nil
+		},
+	}
+	client.ResponseInterceptors = []func(*http.Response) error{
+		func(res *http.Response) error {
+			calls = append(calls, "response 1")
+			servedBy = res.Header.Get("X-Served-By")
+			res.Body = io.NopCloser(strings.NewReader(`[{"id":2,"name":"Vaulatte"}]`))
+			return nil
+		},
+	}
+
+	coffees, err := client.GetCoffees(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if expected := []string{"request 1", "request 2: trace-1", "response 1"}; !reflect.DeepEqual(calls, expected) {
+		t.Errorf("expected interceptor calls %v, got %v", expected, calls)
+	}
+	if traceID != "trace-1" || servedBy != "hashicups-1" {
+		t.Errorf("expected the trace header to be sent and the server header to be seen, got %q and %q", traceID, servedBy)
+	}
+	if len(coffees) != 1 || coffees[0].ID != 2 {
+		t.Errorf("expected the replaced response body to be decoded, got %+v", coffees)
+	}
+
+	// A failing interceptor stops the request and the interceptors after it.
+	calls = nil
+	errBlocked := errors.New("blocked")
+	client.RequestInterceptors = append([]func(*http.Request) error{
+		func(*http.Request) error { return errBlocked },
+	}, client.RequestInterceptors...)
+	if _, err := client.GetCoffees(context.Background()); !errors.Is(err, errBlocked) {
+		t.Errorf("expected the interceptor error, got %v", err)
+	}
+	if len(calls) != 0 || atomic.LoadInt32(&requests) != 1 {
+		t.Errorf("expected no further interceptors or requests, got calls %v and %d requests", calls, requests)
+	}
+}
+
+func TestClientOrderPreflightValidation(t *testing.T) {
+	tests := map[string][]OrderItem{
+		"empty items":       {},
+		"negative quantity": {{Coffee: Coffee{ID: 1}, Quantity: 1}, {Coffee: Coffee{ID: 2}, Quantity: -1}},
+	}
+
+	for name, items := range tests {
+		t.Run(name, func(t *testing.T) {
+			var calls int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				atomic.AddInt32(&calls, 1)
+				_, _ = w.Write([]byte(`{"id":1}`))
+			})
+
+			if _, err := client.CreateOrder(context.Background(), items); !errors.Is(err, ErrInvalidOrder) {
+				t.Errorf("create: expected invalid order error, got %v", err)
+			}
+			if _, err := client.UpdateOrder(context.Background(), "1", items); !errors.Is(err, ErrInvalidOrder) {
+				t.Errorf("update: expected invalid order error, got %v", err)
+			}
+			if calls != 0 {
+				t.Errorf("expected no requests to be sent, got %d", calls)
+			}
+		})
+	}
+}
+
+// recordingCodec is a Codec that counts calls before delegating to
+// encoding/json.
+type recordingCodec struct {
+	marshals   int32
+	unmarshals int32
+}
+
+func (c *recordingCodec) Marshal(v any) ([]byte, error) {
+	atomic.AddInt32(&c.marshals, 1)
+	return json.Marshal(v)
+}
+
+func (c *recordingCodec) Unmarshal(data []byte, v any) error {
+	atomic.AddInt32(&c.unmarshals, 1)
+	return json.Unmarshal(data, v)
+}
+
+func TestClientCodec(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/signin":
+			_, _ = w.Write([]byte(`{"token":"abc"}`))
+		case "/coffees":
+			if r.Method == "GET" {
+				_, _ = w.Write([]byte(`[{"id":1}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"id":1}`))
+		default:
+			_, _ = w.Write([]byte(`{"id":1}`))
+		}
+	})
+	codec := &recordingCodec{}
+	client.Codec = codec
+	client.Auth = AuthStruct{Username: "education", Password: "test123"}
+
+	calls := []struct {
+		name                 string
+		call                 func() error
+		marshals, unmarshals int32
+	}{
+		{"SignIn", func() error { _, err := client.SignIn(context.Background()); return err }, 1, 1},
+		{"GetCoffees", func() error { _, err := client.GetCoffees(context.Background()); return err }, 0, 1},
+		{"CreateCoffee", func() error { _, err := client.CreateCoffee(context.Background(), Coffee{Name: "Test"}); return err }, 1, 1},
+		{"CreateOrder", func() error {
+			_, err := client.CreateOrder(context.Background(), []OrderItem{{Coffee: Coffee{ID: 1}, Quantity: 1}})
+			return err
+		}, 1, 1},
+		{"UpdateOrder", func() error {
+			_, err := client.UpdateOrder(context.Background(), "1", []OrderItem{{Coffee: Coffee{ID: 1}, Quantity: 1}})
+			return err
+		}, 1, 1},
+		{"GetOrder", func() error { _, err := client.GetOrder(context.Background(), "1"); return err }, 0, 1},
+		{"CreateCombo", func() error { _, err := client.CreateCombo(context.Background(), Combo{Name: "Test"}); return err }, 1, 1},
+	}
+
+	for _, test := range calls {
+		marshals, unmarshals := codec.marshals, codec.unmarshals
+		if err := test.call(); err != nil {
+			t.Fatalf("%s: %s", test.name, err)
+		}
+		if got := codec.marshals - marshals; got != test.marshals {
+			t.Errorf("%s: expected %d marshals through the codec, got %d", test.name, test.marshals, got)
+		}
+		if got := codec.unmarshals - unmarshals; got != test.unmarshals {
+			t.Errorf("%s: expected %d unmarshals through the codec, got %d", test.name, test.unmarshals, got)
+		}
+	}
+}
+
+func TestClientCoffeesCursorPagination(t *testing.T) {
+	pages := map[string]string{
+		"":  `{"coffees":[{"id":1},{"id":2}],"next_cursor":"b"}`,
+		"b": `{"coffees":[{"id":3}],"next_cursor":"c"}`,
+		"c": `{"coffees":[{"id":4}],"next_cursor":""}`,
+	}
+
+	tests := map[string]struct {
+		lastPage    string
+		expectedIDs []int
+		expectError bool
+	}{
+		"follows cursors": {
+			lastPage:    pages["c"],
+			expectedIDs: []int{1, 2, 3, 4},
+		},
+		"repeated cursor": {
+			lastPage:    `{"coffees":[{"id":4}],"next_cursor":"b"}`,
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var requests int32
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/capabilities" {
+					_, _ = w.Write([]byte(`{"features":["cursor_pagination"]}`))
+					return
+				}
+
+				if atomic.AddInt32(&requests, 1) > 10 {
+					t.Error("pagination did not stop")
+					w.WriteHeader(http.StatusInternalServerError)
+					return
+				}
+				cursor := r.URL.Query().Get("cursor")
+				if cursor == "c" {
+					_, _ = w.Write([]byte(test.lastPage))
+					return
+				}
+				_, _ = w.Write([]byte(pages[cursor]))
+			})
+			client.capabilities = nil
+
+			coffees, err := client.GetCoffees(context.Background())
+			if test.expectError {
+				if err == nil || !strings.Contains(err.Error(), `cursor "b"`) {
+					t.Errorf("expected a repeated cursor error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			var ids []int
+			for _, coffee := range coffees {
+				ids = append(ids, coffee.ID)
+			}
+			if !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+			if requests := atomic.LoadInt32(&requests); requests != 3 {
+				t.Errorf("expected 3 page requests, got %d", requests)
+			}
+		})
+	}
+}
+
+func TestClientContextCancellation(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// The server notices the client hanging up only once the body is read.
+		_, _ = io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	})
+
+	items := []OrderItem{{Coffee: Coffee{ID: 1}, Quantity: 1}}
+	calls := map[string]func(ctx context.Context) error{
+		"GetOrder":    func(ctx context.Context) error { _, err := client.GetOrder(ctx, "1"); return err },
+		"CreateOrder": func(ctx context.Context) error { _, err := client.CreateOrder(ctx, items); return err },
+		"UpdateOrder": func(ctx context.Context) error { _, err := client.UpdateOrder(ctx, "1", items); return err },
+		"DeleteOrder": func(ctx context.Context) error { return client.DeleteOrder(ctx, "1") },
+		"GetCoffees":  func(ctx context.Context) error { _, err := client.GetCoffees(ctx); return err },
+	}
+
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+			defer cancel()
+
+			if err := call(ctx); !errors.Is(err, context.DeadlineExceeded) {
+				t.Errorf("expected the request to stop at the deadline, got %v", err)
+			}
+		})
+	}
+}
+
+func TestClientRequestLogging(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"UserID":1,"Username":"education","token":"secret-token"}`))
+	})
+	client.Auth = AuthStruct{Username: "education", Password: "secret-password"}
+
+	var output bytes.Buffer
+	ctx := tflogtest.RootLogger(context.Background(), &output)
+	if _, err := client.SignIn(ctx); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	logs := output.String()
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatalf("unable to decode logs: %s", err)
+	}
+
+	var request map[string]any
+	var bodies int
+	for _, entry := range entries {
+		switch entry["@message"] {
+		case "HashiCups API request":
+			request = entry
+		case "Sending HashiCups API request body", "Received HashiCups API response body":
+			bodies++
+			if entry["@level"] != "trace" {
+				t.Errorf("expected bodies to be logged at trace level, got %v", entry["@level"])
+			}
+		}
+	}
+	if request == nil || request["method"] != "POST" || request["status_code"] != float64(200) || request["duration_ms"] == nil {
+		t.Errorf("expected a request log with method, status code, and duration, got %v", request)
+	}
+	if bodies != 2 {
+		t.Errorf("expected request and response bodies to be logged, got %d", bodies)
+	}
+	if strings.Contains(logs, "secret-password") || strings.Contains(logs, "secret-token") {
+		t.Errorf("expected credentials to be masked, got %s", logs)
+	}
+}
+
+// testCredentials is a CredentialProvider returning its tokens in turn.
+type testCredentials struct {
+	tokens []string
+	calls  int
+}
+
+func (p *testCredentials) Token(_ context.Context) (string, error) {
+	if p.calls >= len(p.tokens) {
+		return "", errors.New("no more tokens")
+	}
+	p.calls++
+
+	return p.tokens[p.calls-1], nil
+}
+
+func TestClientCredentialProvider(t *testing.T) {
+	var authorizations []string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		authorizations = append(authorizations, r.Header.Get("Authorization"))
+		if r.Header.Get("Authorization") != "fresh" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	credentials := &testCredentials{tokens: []string{"expired", "fresh"}}
+	client.Credentials = credentials
+	client.Authenticator = &TokenAuthenticator{Token: "unused"}
+
+	for i := 0; i < 2; i++ {
+		if _, err := client.GetCoffees(context.Background()); err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+	}
+
+	// The rejected token is refreshed once and the new one is reused.
+	if expected := []string{"expired", "fresh", "fresh"}; !reflect.DeepEqual(authorizations, expected) {
+		t.Errorf("expected Authorization headers %v, got %v", expected, authorizations)
+	}
+	if credentials.calls != 2 {
+		t.Errorf("expected 2 token requests, got %d", credentials.calls)
+	}
+
+	// A token rejected again is reported rather than refreshed without end.
+	client.credentialsToken = "revoked"
+	credentials.tokens = append(credentials.tokens, "revoked-too")
+	var apiErr *APIError
+	if _, err := client.GetCoffees(context.Background()); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected an unauthorized error, got %v", err)
+	}
+}
+
+func TestNewClientSignsInAgain(t *testing.T) {
+	tests := map[string]struct {
+		scheme string
+		prefix string
+	}{
+		"token":  {},
+		"bearer": {scheme: authSchemeBearer, prefix: "Bearer "},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var signIns int
+			var authorizations []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/signin" {
+					signIns++
+					_, _ = w.Write([]byte(`{"token":"token-` + strconv.Itoa(signIns) + `"}`))
+					return
+				}
+				authorizations = append(authorizations, r.Header.Get("Authorization"))
+				// The token of the first sign in expires before it is used.
+				if r.Header.Get("Authorization") != test.prefix+"token-2" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				_, _ = w.Write([]byte(`[]`))
+			}))
+			t.Cleanup(server.Close)
+
+			client, err := newAuthenticatedClient(context.Background(), test.scheme, server.URL, "education", "test123", nil)
+			if err != nil {
+				t.Fatalf("unable to create client: %s", err)
+			}
+			if _, err := client.GetOrders(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if signIns != 2 {
+				t.Errorf("expected 2 sign ins, got %d", signIns)
+			}
+			if expected := []string{test.prefix + "token-1", test.prefix + "token-2"}; !reflect.DeepEqual(authorizations, expected) {
+				t.Errorf("expected Authorization headers %v, got %v", expected, authorizations)
+			}
+		})
+	}
+}
+
+func TestClientPasswordCredentials(t *testing.T) {
+	var signIns int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/signin" {
+			if r.Header.Get("Authorization") != "" {
+				t.Errorf("expected sign in without credentials, got %q", r.Header.Get("Authorization"))
+			}
+			signIns++
+			_, _ = w.Write([]byte(`{"token":"token-` + strconv.Itoa(signIns) + `"}`))
+			return
+		}
+		// The first token has expired by the time it is used.
+		if r.Header.Get("Authorization") != "token-2" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	client.Credentials = &PasswordCredentials{Client: client, Username: "education", Password: "test123"}
+
+	if _, err := client.GetCoffees(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if signIns != 2 {
+		t.Errorf("expected 2 sign ins, got %d", signIns)
+	}
+}
@@ -0,0 +1,8 @@
+package hashicups
+
+// Codec encodes request bodies and decodes response bodies, allowing a faster
+// JSON implementation to replace encoding/json.
+type Codec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
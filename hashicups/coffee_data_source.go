@@ -0,0 +1,94 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &coffeeDataSource{}
+	_ datasource.DataSourceWithConfigure = &coffeeDataSource{}
+)
+
+func NewCoffeeDataSource() datasource.DataSource {
+	return &coffeeDataSource{}
+}
+
+// coffeeDataSource reads a single coffee by ID. Its schema data is a
+// coffeesModel, the same shape as an entry of the coffees data source.
+type coffeeDataSource struct {
+	client   *Client
+	settings providerSettings
+}
+
+func (d *coffeeDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_coffee"
+}
+
+// Schema defines the schema for the data source.
+func (d *coffeeDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	attributes := coffeesNestedObject().Attributes
+	attributes["id"] = schema.Int64Attribute{
+		Description: "Numeric identifier of the coffee to read.",
+		Required:    true,
+	}
+
+	response.Schema = schema.Schema{
+		Description: "Fetches a single coffee by its identifier.",
+		Attributes:  attributes,
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *coffeeDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer addDeprecationWarnings(&resp.Diagnostics, d.client)
+
+	var config coffeesModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffeeID := config.ID.ValueInt64()
+	coffee, err := d.client.GetCoffee(ctx, int(coffeeID))
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"HashiCups Coffee Not Found",
+			fmt.Sprintf("No coffee with ID %d exists in the HashiCups catalog.", coffeeID),
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read HashiCups Coffee",
+			fmt.Sprintf("Could not read coffee ID %d: %s", coffeeID, err),
+		)
+		return
+	}
+
+	state := newCoffeesModel(*coffee, d.settings)
+	state.ID = config.ID
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *coffeeDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	d.client = data.client
+	d.settings = data.settings
+}
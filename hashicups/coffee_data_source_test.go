@@ -0,0 +1,53 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestCoffeeDataSourceRead(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees/1":
+			_, _ = w.Write([]byte(`{"id":1,"name":"HCP Aeropress","teaser":"Automation in a cup","price":200,"image":"/hashicorp.png","ingredients":[{"ingredient_id":6}]}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	d := &coffeeDataSource{client: client}
+
+	config := func(id int64) *coffeesModel {
+		return &coffeesModel{ID: types.Int64Value(id), Labels: types.MapNull(types.StringType)}
+	}
+
+	resp := readTestDataSource(t, d, config(1))
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state coffeesModel
+	resp.State.Get(context.Background(), &state)
+	if state.ID.ValueInt64() != 1 || state.Name.ValueString() != "HCP Aeropress" || state.Price.ValueFloat64() != 200 {
+		t.Errorf("unexpected coffee: %+v", state)
+	}
+	if got := state.Slug.ValueString(); got != "hcp-aeropress" {
+		t.Errorf("expected slug hcp-aeropress, got %q", got)
+	}
+	if len(state.Ingredients) != 1 || state.Ingredients[0].ID.ValueInt64() != 6 {
+		t.Errorf("unexpected ingredients: %+v", state.Ingredients)
+	}
+
+	resp = readTestDataSource(t, d, config(42))
+	errs := resp.Diagnostics.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected one not found error, got %v", resp.Diagnostics)
+	}
+	if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("id")) || d.Summary() != "HashiCups Coffee Not Found" {
+		t.Errorf("expected not found error at id, got %v", errs[0])
+	}
+}
@@ -0,0 +1,323 @@
+package hashicups
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &coffeeResource{}
+	_ resource.ResourceWithConfigure   = &coffeeResource{}
+	_ resource.ResourceWithImportState = &coffeeResource{}
+)
+
+// coffeeResourceIngredientAttrTypes is the shape of an ingredients element.
+var coffeeResourceIngredientAttrTypes = map[string]attr.Type{
+	"id": types.Int64Type,
+}
+
+type coffeeResource struct {
+	client   *Client
+	settings providerSettings
+}
+
+// coffeeResourceModel maps the resource schema data.
+type coffeeResourceModel struct {
+	ID          types.String  `tfsdk:"id"`
+	Name        types.String  `tfsdk:"name"`
+	Teaser      types.String  `tfsdk:"teaser"`
+	Description types.String  `tfsdk:"description"`
+	Price       types.Float64 `tfsdk:"price"`
+	Image       types.String  `tfsdk:"image"`
+	Ingredients types.List    `tfsdk:"ingredients"`
+}
+
+// coffeeResourceIngredientModel maps an element of the ingredients list.
+type coffeeResourceIngredientModel struct {
+	ID types.Int64 `tfsdk:"id"`
+}
+
+func NewCoffeeResource() resource.Resource {
+	return &coffeeResource{}
+}
+
+func (r *coffeeResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_coffee"
+}
+
+// Schema defines the schema for the resource.
+func (r *coffeeResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Manages a coffee on the HashiCups menu.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Numeric identifier of the coffee.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Product name of the coffee.",
+			},
+			"teaser": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Fun tagline for the coffee.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Product description of the coffee.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"price": schema.Float64Attribute{
+				Required:    true,
+				Description: "Suggested cost of the coffee.",
+			},
+			"image": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "URI for an image of the coffee.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"ingredients": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Ingredients of the coffee.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Required:    true,
+							Description: "Numeric identifier of the ingredient.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func (r *coffeeResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "create the coffee")
+		return
+	}
+
+	var plan coffeeResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffee, diags := plan.toCoffee(ctx)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateCoffee(ctx, coffee)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Creating HashiCups Coffee",
+			"Could not create coffee, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = plan.fromCoffee(ctx, created)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *coffeeResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	var state coffeeResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffeeID, diags := state.coffeeID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffee, err := r.client.GetCoffee(ctx, coffeeID)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Reading HashiCups Coffee",
+			"Could not read HashiCups coffee ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	diags = state.fromCoffee(ctx, coffee)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, &state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *coffeeResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "update the coffee")
+		return
+	}
+
+	var plan coffeeResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffeeID, diags := plan.coffeeID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffee, diags := plan.toCoffee(ctx)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateCoffee(ctx, coffeeID, coffee)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Updating HashiCups Coffee",
+			"Could not update coffee, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = plan.fromCoffee(ctx, updated)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *coffeeResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "delete the coffee")
+		return
+	}
+
+	var state coffeeResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	coffeeID, diags := state.coffeeID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteCoffee(ctx, coffeeID)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Deleting HashiCups Coffee",
+			"Could not delete coffee, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *coffeeResource) Configure(_ context.Context, request resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	r.client = data.client
+	r.settings = data.settings
+}
+
+func (r *coffeeResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), request, response)
+}
+
+// coffeeID parses the numeric coffee ID of the model.
+func (m coffeeResourceModel) coffeeID() (int, diag.Diagnostics) {
+	return parseResourceID(m.ID, "Coffee")
+}
+
+// toCoffee builds the API request body from the model.
+func (m coffeeResourceModel) toCoffee(ctx context.Context) (Coffee, diag.Diagnostics) {
+	var ingredients []coffeeResourceIngredientModel
+	diags := m.Ingredients.ElementsAs(ctx, &ingredients, false)
+
+	coffee := Coffee{
+		Name:        m.Name.ValueString(),
+		Teaser:      m.Teaser.ValueString(),
+		Description: m.Description.ValueString(),
+		Price:       m.Price.ValueFloat64(),
+		Image:       m.Image.ValueString(),
+	}
+	for _, ingredient := range ingredients {
+		coffee.Ingredient = append(coffee.Ingredient, Ingredient{ID: int(ingredient.ID.ValueInt64())})
+	}
+
+	return coffee, diags
+}
+
+// fromCoffee maps an API coffee onto the model. Ingredients stay null when
+// none were configured and the API reports none.
+func (m *coffeeResourceModel) fromCoffee(ctx context.Context, coffee *Coffee) diag.Diagnostics {
+	m.ID = types.StringValue(strconv.Itoa(coffee.ID))
+	m.Name = types.StringValue(coffee.Name)
+	m.Teaser = types.StringValue(coffee.Teaser)
+	m.Description = types.StringValue(coffee.Description)
+	m.Price = types.Float64Value(coffee.Price)
+	m.Image = types.StringValue(coffee.Image)
+
+	elementType := types.ObjectType{AttrTypes: coffeeResourceIngredientAttrTypes}
+	if len(coffee.Ingredient) == 0 && m.Ingredients.IsNull() {
+		m.Ingredients = types.ListNull(elementType)
+		return nil
+	}
+
+	ingredients := make([]coffeeResourceIngredientModel, 0, len(coffee.Ingredient))
+	for _, ingredient := range coffee.Ingredient {
+		ingredients = append(ingredients, coffeeResourceIngredientModel{ID: types.Int64Value(int64(ingredient.ID))})
+	}
+
+	var diags diag.Diagnostics
+	m.Ingredients, diags = types.ListValueFrom(ctx, elementType, ingredients)
+
+	return diags
+}
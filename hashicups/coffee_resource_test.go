@@ -0,0 +1,106 @@
+package hashicups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newTestCoffeeClient returns a client backed by an in-memory coffee API.
+func newTestCoffeeClient(t *testing.T) *Client {
+	t.Helper()
+
+	return newTestClient(t, newTestCRUDHandler("/coffees", "Deleted coffee", func(coffee *Coffee, id int) { coffee.ID = id }))
+}
+
+// testCoffeeIngredients builds an ingredients list from ingredient IDs.
+func testCoffeeIngredients(ids ...int64) types.List {
+	elementType := types.ObjectType{AttrTypes: coffeeResourceIngredientAttrTypes}
+	elements := make([]attr.Value, 0, len(ids))
+	for _, id := range ids {
+		elements = append(elements, types.ObjectValueMust(coffeeResourceIngredientAttrTypes, map[string]attr.Value{
+			"id": types.Int64Value(id),
+		}))
+	}
+
+	return types.ListValueMust(elementType, elements)
+}
+
+func TestCoffeeResourceCRUD(t *testing.T) {
+	planned := coffeeResourceModel{
+		ID:          types.StringUnknown(),
+		Name:        types.StringValue("Packer Spiced Latte"),
+		Teaser:      types.StringValue("Packed with goodness to spice up your images"),
+		Description: types.StringUnknown(),
+		Price:       types.Float64Value(350),
+		Image:       types.StringUnknown(),
+		Ingredients: testCoffeeIngredients(1, 2),
+	}
+
+	// Update price and ingredients
+	created, read, updated := runTestResourceCRUD(t, &coffeeResource{client: newTestCoffeeClient(t)}, planned, func(m coffeeResourceModel) coffeeResourceModel {
+		m.Price = types.Float64Value(400)
+		m.Ingredients = testCoffeeIngredients(3)
+		return m
+	})
+
+	if created.ID.ValueString() != "1" {
+		t.Fatalf("expected coffee ID 1, got %s", created.ID)
+	}
+	if created.Description.IsUnknown() || created.Image.IsUnknown() {
+		t.Errorf("expected unset optional attributes to be known after create, got %+v", created)
+	}
+	if !read.Ingredients.Equal(planned.Ingredients) || read.Name.ValueString() != "Packer Spiced Latte" {
+		t.Errorf("unexpected coffee after read: %+v", read)
+	}
+	if updated.Price.ValueFloat64() != 400 || !updated.Ingredients.Equal(testCoffeeIngredients(3)) {
+		t.Errorf("unexpected coffee after update: %+v", updated)
+	}
+}
+
+func TestCoffeeResourceImport(t *testing.T) {
+	ctx := context.Background()
+	client := newTestCoffeeClient(t)
+	if _, err := client.CreateCoffee(ctx, Coffee{Name: "HCP Aeropress", Price: 200}); err != nil {
+		t.Fatalf("unable to create coffee: %s", err)
+	}
+
+	r := &coffeeResource{client: client}
+	s := testResourceSchema(t, r)
+
+	tests := map[string]struct {
+		id          string
+		expectError bool
+	}{
+		"numeric":     {id: "1"},
+		"non-numeric": {id: "aeropress", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			importResp := &resource.ImportStateResponse{State: testState(t, s, nil)}
+			r.ImportState(ctx, resource.ImportStateRequest{ID: test.id}, importResp)
+			if importResp.Diagnostics.HasError() {
+				t.Fatalf("import: %v", importResp.Diagnostics)
+			}
+
+			readResp := &resource.ReadResponse{State: importResp.State}
+			r.Read(ctx, resource.ReadRequest{State: importResp.State}, readResp)
+			if readResp.Diagnostics.HasError() != test.expectError {
+				t.Fatalf("expected error %t, got %v", test.expectError, readResp.Diagnostics)
+			}
+			if test.expectError {
+				return
+			}
+
+			var imported coffeeResourceModel
+			readResp.State.Get(ctx, &imported)
+			if imported.Name.ValueString() != "HCP Aeropress" || imported.Price.ValueFloat64() != 200 || !imported.Ingredients.IsNull() {
+				t.Errorf("unexpected coffee after import: %+v", imported)
+			}
+		})
+	}
+}
@@ -1,26 +1,192 @@
 package hashicups
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"sync"
 )
 
+// CoffeeFilter selects coffees by name and price. Zero fields match every
+// coffee.
+type CoffeeFilter struct {
+	// Name matches coffees whose name contains it, ignoring case.
+	Name     string
+	MinPrice *float64
+	MaxPrice *float64
+}
+
+// IsZero reports whether the filter matches every coffee.
+func (f CoffeeFilter) IsZero() bool {
+	return f.Name == "" && f.MinPrice == nil && f.MaxPrice == nil
+}
+
+// Matches reports whether the coffee passes the filter.
+func (f CoffeeFilter) Matches(coffee Coffee) bool {
+	if f.Name != "" && !strings.Contains(strings.ToLower(coffee.Name), strings.ToLower(f.Name)) {
+		return false
+	}
+	if f.MinPrice != nil && coffee.Price < *f.MinPrice {
+		return false
+	}
+	if f.MaxPrice != nil && coffee.Price > *f.MaxPrice {
+		return false
+	}
+
+	return true
+}
+
+// query returns the filter as coffees query parameters.
+func (f CoffeeFilter) query() url.Values {
+	query := url.Values{}
+	if f.Name != "" {
+		query.Set("name", f.Name)
+	}
+	if f.MinPrice != nil {
+		query.Set("min_price", strconv.FormatFloat(*f.MinPrice, 'f', -1, 64))
+	}
+	if f.MaxPrice != nil {
+		query.Set("max_price", strconv.FormatFloat(*f.MaxPrice, 'f', -1, 64))
+	}
+
+	return query
+}
+
 // GetCoffees - Returns list of coffees (no auth required)
-func (c *Client) GetCoffees() ([]Coffee, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/coffees", c.HostURL), nil)
+func (c *Client) GetCoffees(ctx context.Context) ([]Coffee, error) {
+	return c.listCoffees(ctx, url.Values{})
+}
+
+// coffeesCacheEntry holds the catalog read for one cache key. Its mutex is
+// held while the catalog is read so concurrent readers wait for the result.
+type coffeesCacheEntry struct {
+	mu      sync.Mutex
+	coffees []Coffee
+}
+
+// GetCoffeesCached - Returns list of coffees, reading the catalog once per
+// key for the lifetime of the client. Failed reads are not cached.
+func (c *Client) GetCoffeesCached(ctx context.Context, key string) ([]Coffee, error) {
+	c.coffeesCacheMu.Lock()
+	if c.coffeesCache == nil {
+		c.coffeesCache = make(map[string]*coffeesCacheEntry)
+	}
+	entry, ok := c.coffeesCache[key]
+	if !ok {
+		entry = &coffeesCacheEntry{}
+		c.coffeesCache[key] = entry
+	}
+	c.coffeesCacheMu.Unlock()
+
+	entry.mu.Lock()
+	defer entry.mu.Unlock()
+
+	if entry.coffees == nil {
+		coffees, err := c.GetCoffees(ctx)
+		if err != nil {
+			return nil, err
+		}
+		entry.coffees = coffees
+	}
+
+	// Limit the capacity so appends by callers copy rather than share.
+	return entry.coffees[:len(entry.coffees):len(entry.coffees)], nil
+}
+
+// GetCoffeesFiltered - Returns list of coffees, asking the server to apply
+// filter when it supports coffee filters. Other servers return the full
+// catalog, so callers should still check each coffee with filter.Matches
+func (c *Client) GetCoffeesFiltered(ctx context.Context, filter CoffeeFilter) ([]Coffee, error) {
+	if filter.IsZero() || !c.supports(ctx, CapabilityCoffeeFilters) {
+		return c.GetCoffees(ctx)
+	}
+
+	return c.listCoffees(ctx, filter.query())
+}
+
+// coffeesPage is a page of coffees returned with cursor pagination.
+type coffeesPage struct {
+	Coffees    []Coffee `json:"coffees"`
+	NextCursor string   `json:"next_cursor"`
+}
+
+// listCoffees reads the coffees matching query from the client host,
+// following pagination cursors when the server supports them.
+func (c *Client) listCoffees(ctx context.Context, query url.Values) ([]Coffee, error) {
+	if !c.supports(ctx, CapabilityCursorPagination) {
+		return c.getCoffees(ctx, coffeesURL(c.baseURL(), query))
+	}
+
+	coffees := []Coffee{}
+	seen := map[string]bool{}
+	for cursor := ""; ; {
+		if cursor != "" {
+			query.Set("cursor", cursor)
+		}
+
+		req, err := http.NewRequestWithContext(ctx, "GET", coffeesURL(c.baseURL(), query), nil)
+		if err != nil {
+			return nil, err
+		}
+
+		body, err := c.doRequestWithRetry(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+
+		page := coffeesPage{}
+		err = c.decode(body, &page)
+		if err != nil {
+			return nil, err
+		}
+		coffees = append(coffees, page.Coffees...)
+
+		if page.NextCursor == "" {
+			return coffees, nil
+		}
+		if seen[page.NextCursor] {
+			return nil, fmt.Errorf("coffees pagination cursor %q was returned more than once", page.NextCursor)
+		}
+		seen[page.NextCursor] = true
+		cursor = page.NextCursor
+	}
+}
+
+// coffeesURL returns the coffees endpoint of host with query.
+func coffeesURL(host string, query url.Values) string {
+	if len(query) == 0 {
+		return host + "/coffees"
+	}
+
+	return host + "/coffees?" + query.Encode()
+}
+
+// GetCoffeesFromHost - Returns list of coffees from another HashiCups
+// instance, using the client settings. The catalog is public, so the client
+// credentials are never sent to the other host.
+func (c *Client) GetCoffeesFromHost(ctx context.Context, host string) ([]Coffee, error) {
+	ctx = context.WithValue(ctx, withoutCredentialsKey{}, true)
+	return c.getCoffees(ctx, coffeesURL(host, nil))
+}
+
+// getCoffees reads the list of coffees at rawURL.
+func (c *Client) getCoffees(ctx context.Context, rawURL string) ([]Coffee, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := c.doRequest(req)
+	body, err := c.doRequestWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	coffees := []Coffee{}
-	err = json.Unmarshal(body, &coffees)
+	err = c.decode(body, &coffees)
 	if err != nil {
 		return nil, err
 	}
@@ -28,20 +194,47 @@ func (c *Client) GetCoffees() ([]Coffee, error) {
 	return coffees, nil
 }
 
+// GetCoffee - Returns a specific coffee (no auth required)
+func (c *Client) GetCoffee(ctx context.Context, coffeeID int) (*Coffee, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/coffees/%d", c.baseURL(), coffeeID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	coffee := Coffee{}
+	err = c.decode(body, &coffee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &coffee, nil
+}
+
+// GetIngredients - Returns the ingredients of a coffee, with their names
+// and quantities (no auth required)
+func (c *Client) GetIngredients(ctx context.Context, coffeeID int) ([]Ingredient, error) {
+	return c.GetCoffeeIngredients(ctx, strconv.Itoa(coffeeID))
+}
+
 // GetCoffeeIngredients - Returns list of coffee ingredients (no auth required)
-func (c *Client) GetCoffeeIngredients(coffeeID string) ([]Ingredient, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/coffees/%s/ingredients", c.HostURL, coffeeID), nil)
+func (c *Client) GetCoffeeIngredients(ctx context.Context, coffeeID string) ([]Ingredient, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/coffees/%s/ingredients", c.baseURL(), coffeeID), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := c.doRequest(req)
+	body, err := c.doRequestWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	ingredients := []Ingredient{}
-	err = json.Unmarshal(body, &ingredients)
+	err = c.decode(body, &ingredients)
 	if err != nil {
 		return nil, err
 	}
@@ -49,14 +242,37 @@ func (c *Client) GetCoffeeIngredients(coffeeID string) ([]Ingredient, error) {
 	return ingredients, nil
 }
 
+// RefreshImageURL - Returns a freshly signed image URL for a coffee
+func (c *Client) RefreshImageURL(ctx context.Context, coffeeID int) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/coffees/%d/image", c.baseURL(), coffeeID), nil)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return "", err
+	}
+
+	image := struct {
+		URL string `json:"url"`
+	}{}
+	err = c.decode(body, &image)
+	if err != nil {
+		return "", err
+	}
+
+	return image.URL, nil
+}
+
 // CreateCoffee - Create new coffee
-func (c *Client) CreateCoffee(coffee Coffee) (*Coffee, error) {
-	rb, err := json.Marshal(coffee)
+func (c *Client) CreateCoffee(ctx context.Context, coffee Coffee) (*Coffee, error) {
+	rb, err := c.encode(coffee)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/coffees", c.HostURL), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/coffees", c.baseURL()), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -67,7 +283,7 @@ func (c *Client) CreateCoffee(coffee Coffee) (*Coffee, error) {
 	}
 
 	newCoffee := Coffee{}
-	err = json.Unmarshal(body, &newCoffee)
+	err = c.decode(body, &newCoffee)
 	if err != nil {
 		return nil, err
 	}
@@ -75,8 +291,53 @@ func (c *Client) CreateCoffee(coffee Coffee) (*Coffee, error) {
 	return &newCoffee, nil
 }
 
+// UpdateCoffee - Updates a coffee
+func (c *Client) UpdateCoffee(ctx context.Context, coffeeID int, coffee Coffee) (*Coffee, error) {
+	rb, err := c.encode(coffee)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("%s/coffees/%d", c.baseURL(), coffeeID), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCoffee := Coffee{}
+	err = c.decode(body, &updatedCoffee)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedCoffee, nil
+}
+
+// DeleteCoffee - Deletes a coffee
+func (c *Client) DeleteCoffee(ctx context.Context, coffeeID int) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/coffees/%d", c.baseURL(), coffeeID), nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > 0 && string(body) != "Deleted coffee" {
+		return errors.New(string(body))
+	}
+
+	return nil
+}
+
 // CreateCoffeeIngredient - Create new coffee ingredient
-func (c *Client) CreateCoffeeIngredient(coffee Coffee, ingredient Ingredient) (*Ingredient, error) {
+func (c *Client) CreateCoffeeIngredient(ctx context.Context, coffee Coffee, ingredient Ingredient) (*Ingredient, error) {
 	reqBody := struct {
 		CoffeeID     int    `json:"coffee_id"`
 		IngredientID int    `json:"ingredient_id"`
@@ -88,12 +349,12 @@ func (c *Client) CreateCoffeeIngredient(coffee Coffee, ingredient Ingredient) (*
 		Quantity:     ingredient.Quantity,
 		Unit:         ingredient.Unit,
 	}
-	rb, err := json.Marshal(reqBody)
+	rb, err := c.encode(reqBody)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/coffees/%d/ingredients", c.HostURL, coffee.ID), strings.NewReader(string(rb)))
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/coffees/%d/ingredients", c.baseURL(), coffee.ID), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -104,7 +365,7 @@ func (c *Client) CreateCoffeeIngredient(coffee Coffee, ingredient Ingredient) (*
 	}
 
 	newIngredient := Ingredient{}
-	err = json.Unmarshal(body, &newIngredient)
+	err = c.decode(body, &newIngredient)
 	if err != nil {
 		return nil, err
 	}
@@ -2,10 +2,25 @@ package hashicups
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"golang.org/x/text/unicode/norm"
 )
 
 // Ensure the implementation satisfies the expected interfaces.
@@ -19,24 +34,60 @@ func NewCoffeesDataSource() datasource.DataSource {
 }
 
 type coffeesDataSource struct {
-	client *Client
+	client   *Client
+	settings providerSettings
 }
 
 // coffeesDataSourceModel maps the data source schema data.
 type coffeesDataSourceModel struct {
-	ID      types.String   `tfsdk:"id"`
-	Coffees []coffeesModel `tfsdk:"coffees"`
+	ID                    types.String            `tfsdk:"id"`
+	RefreshImageURLs      types.Bool              `tfsdk:"refresh_image_urls"`
+	IncludeTeaser         types.Bool              `tfsdk:"include_teaser"`
+	EnrichIngredients     types.Bool              `tfsdk:"enrich_ingredients"`
+	CacheKey              types.String            `tfsdk:"cache_key"`
+	AvailableNow          types.Bool              `tfsdk:"available_now"`
+	NewOnly               types.Bool              `tfsdk:"new_only"`
+	LabelSelector         types.String            `tfsdk:"label_selector"`
+	OriginFilter          types.String            `tfsdk:"origin_filter"`
+	RoastFilter           types.String            `tfsdk:"roast_filter"`
+	NameFilter            types.String            `tfsdk:"name_filter"`
+	MinPrice              types.Float64           `tfsdk:"min_price"`
+	MaxPrice              types.Float64           `tfsdk:"max_price"`
+	ExtraHosts            []types.String          `tfsdk:"extra_hosts"`
+	MaxPricePerIngredient types.Float64           `tfsdk:"max_price_per_ingredient"`
+	ExcludeAllergens      []types.String          `tfsdk:"exclude_allergens"`
+	Limit                 types.Int64             `tfsdk:"limit"`
+	Coffees               []coffeesModel          `tfsdk:"coffees"`
+	CoffeesByID           map[string]coffeesModel `tfsdk:"coffees_by_id"`
+	AllIngredientNames    []types.String          `tfsdk:"all_ingredient_names"`
+	FetchedAt             types.String            `tfsdk:"fetched_at"`
+	CatalogChecksum       types.String            `tfsdk:"catalog_checksum"`
+	AveragePrice          types.Float64           `tfsdk:"average_price"`
+	MinCatalogPrice       types.Float64           `tfsdk:"min_catalog_price"`
+	MaxCatalogPrice       types.Float64           `tfsdk:"max_catalog_price"`
 }
 
 // coffeesModel maps coffees schema data.
 type coffeesModel struct {
-	ID          types.Int64               `tfsdk:"id"`
-	Name        types.String              `tfsdk:"name"`
-	Teaser      types.String              `tfsdk:"teaser"`
-	Description types.String              `tfsdk:"description"`
-	Price       types.Float64             `tfsdk:"price"`
-	Image       types.String              `tfsdk:"image"`
-	Ingredients []coffeesIngredientsModel `tfsdk:"ingredients"`
+	ID                 types.Int64               `tfsdk:"id"`
+	Name               types.String              `tfsdk:"name"`
+	Slug               types.String              `tfsdk:"slug"`
+	LocalizedName      types.String              `tfsdk:"localized_name"`
+	Teaser             types.String              `tfsdk:"teaser"`
+	Description        types.String              `tfsdk:"description"`
+	Price              types.Float64             `tfsdk:"price"`
+	DiscountPrice      types.Float64             `tfsdk:"discount_price"`
+	Image              types.String              `tfsdk:"image"`
+	AvailableFrom      types.String              `tfsdk:"available_from"`
+	AvailableUntil     types.String              `tfsdk:"available_until"`
+	Labels             types.Map                 `tfsdk:"labels"`
+	Origin             types.String              `tfsdk:"origin"`
+	RoastLevel         types.String              `tfsdk:"roast_level"`
+	Process            types.String              `tfsdk:"process"`
+	PricePerIngredient types.Float64             `tfsdk:"price_per_ingredient"`
+	IsNew              types.Bool                `tfsdk:"is_new"`
+	Allergens          []types.String            `tfsdk:"allergens"`
+	Ingredients        []coffeesIngredientsModel `tfsdk:"ingredients"`
 }
 
 // coffeesIngredientsModel maps coffee ingredients data
@@ -57,47 +108,207 @@ func (c *coffeesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 				Computed:    true,
 				Description: "Placeholder identifier attribute.",
 			},
+			"refresh_image_urls": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Request a freshly signed image URL for each coffee. If a refresh fails, the URL from the catalog is kept.",
+			},
+			"include_teaser": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Include the teaser of each coffee. Set to false to leave teaser null and keep the state smaller. Defaults to true.",
+			},
+			"enrich_ingredients": schema.BoolAttribute{
+				Optional: true,
+				Description: "Read the ingredients of each returned coffee from the API to fill all_ingredient_names, one request per coffee. " +
+					"Coffees whose ingredients cannot be read are reported as warnings.",
+			},
+			"cache_key": schema.StringAttribute{
+				Optional: true,
+				Description: "Share the coffee catalog between hashicups_coffees data sources with the same cache_key, reading it from the API " +
+					"only once per Terraform run. The full catalog is read and every filter is applied by the provider.",
+			},
+			"available_now": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only return coffees whose availability window includes the current time.",
+			},
+			"new_only": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Only return coffees with is_new set.",
+			},
+			"label_selector": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return coffees whose labels match every comma-separated `key=value` pair, such as `origin=colombia,roast=dark`.",
+			},
+			"origin_filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return coffees whose bean origin matches, ignoring case.",
+			},
+			"roast_filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return coffees whose roast level matches, ignoring case.",
+			},
+			"name_filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "Only return coffees whose name contains this value, ignoring case.",
+			},
+			"min_price": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Only return coffees whose price is at least this value.",
+			},
+			"max_price": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Only return coffees whose price is at most this value.",
+			},
+			"max_price_per_ingredient": schema.Float64Attribute{
+				Optional:    true,
+				Description: "Only return coffees whose price_per_ingredient is at most this value. Coffees without ingredients are excluded.",
+			},
+			"exclude_allergens": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "Leave out coffees containing any of these allergens, ignoring case. Coffees without allergen data are kept.",
+			},
+			"limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Return at most this many coffees, the first ones matching the filters. A warning reports how many were left out.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"extra_hosts": schema.ListAttribute{
+				ElementType: types.StringType,
+				Optional:    true,
+				Description: "URIs of additional HashiCups API instances to read coffees from. Results are merged with the provider host, " +
+					"keeping the first coffee seen for each ID. The provider credentials are not sent to these hosts. Hosts that cannot be read are reported as warnings.",
+			},
+			"catalog_checksum": schema.StringAttribute{
+				Computed: true,
+				Description: "SHA-256 checksum of the coffee catalog, before filtering. When the API filters by name_filter, min_price, and max_price itself, only the matching coffees are covered. " +
+					"It does not depend on the order coffees are returned in and changes whenever a coffee is added, removed, or modified.",
+			},
+			"average_price": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Mean price of the returned coffees, after filtering. Null when no coffees are returned.",
+			},
+			"min_catalog_price": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Lowest price of the returned coffees, after filtering. Null when no coffees are returned.",
+			},
+			"max_catalog_price": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Highest price of the returned coffees, after filtering. Null when no coffees are returned.",
+			},
+			"all_ingredient_names": schema.ListAttribute{
+				ElementType: types.StringType,
+				Computed:    true,
+				Description: "Sorted, distinct names of the ingredients of the returned coffees. Empty unless enrich_ingredients is set " +
+					"or the catalog includes ingredient names.",
+			},
+			"fetched_at": schema.StringAttribute{
+				Computed:    true,
+				Description: "RFC3339 timestamp at which the coffees were read from the API.",
+			},
+			"coffees_by_id": schema.MapNestedAttribute{
+				Computed:     true,
+				Description:  "The coffees keyed by their numeric identifier as a string, such as `coffees_by_id[\"3\"]`.",
+				NestedObject: coffeesNestedObject(),
+			},
 			"coffees": schema.ListNestedAttribute{
+				Computed:     true,
+				Description:  "List of coffees. Empty when no coffees match, or null with the provider empty_lists_as_null setting.",
+				NestedObject: coffeesNestedObject(),
+			},
+		},
+	}
+}
+
+// coffeesNestedObject returns the schema of a coffee in the coffees list and
+// the coffees_by_id map.
+func coffeesNestedObject() schema.NestedAttributeObject {
+	return schema.NestedAttributeObject{
+		Attributes: map[string]schema.Attribute{
+			"id": schema.Int64Attribute{
+				Description: "Numeric identifier of the coffee.",
+				Computed:    true,
+			},
+			"name": schema.StringAttribute{
+				Description: "Product name of the coffee.",
+				Computed:    true,
+			},
+			"slug": schema.StringAttribute{
+				Description: "URL-friendly form of the name, such as `hcp-aeropress`: lowercased, with accents and punctuation removed and spaces replaced by hyphens.",
+				Computed:    true,
+			},
+			"localized_name": schema.StringAttribute{
+				Description: "Product name in the provider accept_language, falling back to name when the API does not localize it.",
+				Computed:    true,
+			},
+			"teaser": schema.StringAttribute{
+				Description: "Fun tagline for the coffee.",
+				Computed:    true,
+			},
+			"description": schema.StringAttribute{
+				Description: "Product description of the coffee.",
+				Computed:    true,
+			},
+			"price": schema.Float64Attribute{
+				Description: "Suggested cost of the coffee.",
+				Computed:    true,
+			},
+			"discount_price": schema.Float64Attribute{
+				Description: "Price of the coffee after the provider promo_percent discount, rounded to cents by the provider price_rounding mode.",
+				Computed:    true,
+			},
+			"image": schema.StringAttribute{
+				Description: "URI for an image of the coffee.",
+				Computed:    true,
+			},
+			"available_from": schema.StringAttribute{
+				Description: "RFC3339 timestamp from which a seasonal coffee is available. Null when always available.",
+				Computed:    true,
+			},
+			"available_until": schema.StringAttribute{
+				Description: "RFC3339 timestamp until which a seasonal coffee is available. Null when always available.",
+				Computed:    true,
+			},
+			"labels": schema.MapAttribute{
+				ElementType: types.StringType,
+				Description: "Metadata labels of the coffee. Empty when the coffee has no labels.",
+				Computed:    true,
+			},
+			"origin": schema.StringAttribute{
+				Description: "Origin of the coffee beans, if known.",
+				Computed:    true,
+			},
+			"roast_level": schema.StringAttribute{
+				Description: "Roast level of the coffee beans, if known.",
+				Computed:    true,
+			},
+			"process": schema.StringAttribute{
+				Description: "Processing method of the coffee beans, if known.",
+				Computed:    true,
+			},
+			"price_per_ingredient": schema.Float64Attribute{
+				Description: "Price of the coffee divided by its number of ingredients. Null when the coffee has no ingredients.",
+				Computed:    true,
+			},
+			"is_new": schema.BoolAttribute{
+				Description: "Whether the coffee was added to the catalog within the provider new_window. False when the API does not report when it was added.",
+				Computed:    true,
+			},
+			"allergens": schema.ListAttribute{
+				ElementType: types.StringType,
+				Description: "Allergens the coffee contains. Null when the API has no allergen data for the coffee.",
+				Computed:    true,
+			},
+			"ingredients": schema.ListNestedAttribute{
+				Description: "List of ingredients in the coffee. Empty when the coffee has none, or null with the provider empty_lists_as_null setting.",
 				Computed:    true,
-				Description: "List of coffees.",
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"id": schema.Int64Attribute{
-							Description: "Numeric identifier of the coffee.",
+							Description: "Numeric identifier of the coffee ingredient.",
 							Computed:    true,
 						},
-						"name": schema.StringAttribute{
-							Description: "Product name of the coffee.",
-							Computed:    true,
-						},
-						"teaser": schema.StringAttribute{
-							Description: "Fun tagline for the coffee.",
-							Computed:    true,
-						},
-						"description": schema.StringAttribute{
-							Description: "Product description of the coffee.",
-							Computed:    true,
-						},
-						"price": schema.Float64Attribute{
-							Description: "Suggested cost of the coffee.",
-							Computed:    true,
-						},
-						"image": schema.StringAttribute{
-							Description: "URI for an image of the coffee.",
-							Computed:    true,
-						},
-						"ingredients": schema.ListNestedAttribute{
-							Description: "List of ingredients in the coffee.",
-							Computed:    true,
-							NestedObject: schema.NestedAttributeObject{
-								Attributes: map[string]schema.Attribute{
-									"id": schema.Int64Attribute{
-										Description: "Numeric identifier of the coffee ingredient.",
-										Computed:    true,
-									},
-								},
-							},
-						},
 					},
 				},
 			},
@@ -106,52 +317,441 @@ func (c *coffeesDataSource) Schema(_ context.Context, _ datasource.SchemaRequest
 }
 
 // Read refreshes the Terraform state with the latest data.
-func (c *coffeesDataSource) Read(ctx context.Context, _ datasource.ReadRequest, resp *datasource.ReadResponse) {
+func (c *coffeesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer addDeprecationWarnings(&resp.Diagnostics, c.client)
+
 	var state coffeesDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	var extraHosts []string
+	for _, host := range state.ExtraHosts {
+		extraHosts = append(extraHosts, host.ValueString())
+	}
+
+	filter := CoffeeFilter{
+		Name:     state.NameFilter.ValueString(),
+		MinPrice: state.MinPrice.ValueFloat64Pointer(),
+		MaxPrice: state.MaxPrice.ValueFloat64Pointer(),
+	}
+
+	var coffees []Coffee
+	var err error
+	if !state.CacheKey.IsNull() {
+		coffees, err = c.client.GetCoffeesCached(ctx, state.CacheKey.ValueString())
+	} else {
+		coffees, err = c.client.GetCoffeesFiltered(ctx, filter)
+	}
+	if err != nil {
+		if len(extraHosts) == 0 {
+			resp.Diagnostics.AddError(
+				"Unable to Read HashiCups Coffees",
+				err.Error(),
+			)
+			return
+		}
 
-	coffees, err := c.client.GetCoffees()
+		resp.Diagnostics.AddWarning(
+			"Unable to Read HashiCups Coffees From Host",
+			fmt.Sprintf("Could not read coffees from %s, continuing with the remaining hosts: %s", c.client.HostURL, err),
+		)
+	}
+
+	if len(extraHosts) > 0 {
+		coffees = c.mergeExtraHosts(ctx, coffees, err == nil, extraHosts, &resp.Diagnostics)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	checksum, err := catalogChecksum(coffees)
 	if err != nil {
 		resp.Diagnostics.AddError(
-			"Unable to Read HashiCups Coffees",
+			"Unable to Checksum HashiCups Coffees",
+			err.Error(),
+		)
+		return
+	}
+	state.CatalogChecksum = types.StringValue(checksum)
+
+	selector, err := parseLabelSelector(state.LabelSelector.ValueString())
+	if err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("label_selector"),
+			"Invalid Label Selector",
 			err.Error(),
 		)
 		return
 	}
 
+	now := c.settings.currentTime()
+	ingredientNames := map[string]bool{}
+	hasIngredients := false
+	matched := 0
+
 	// Map response body to model
 	for _, coffee := range coffees {
-		coffeeState := coffeesModel{
-			ID:          types.Int64Value(int64(coffee.ID)),
-			Name:        types.StringValue(coffee.Name),
-			Teaser:      types.StringValue(coffee.Teaser),
-			Description: types.StringValue(coffee.Description),
-			Price:       types.Float64Value(coffee.Price),
-			Image:       types.StringValue(coffee.Image),
+		if state.AvailableNow.ValueBool() && !coffee.AvailableAt(now) {
+			continue
+		}
+		if state.NewOnly.ValueBool() && !coffee.IsNewAt(now, c.settings.newWindow) {
+			continue
+		}
+		// Extra hosts and servers without filter support return coffees
+		// that do not match.
+		if !filter.Matches(coffee) {
+			continue
+		}
+		if !matchesLabels(coffee.Metadata, selector) {
+			continue
+		}
+		if !matchesOptional(coffee.Origin, state.OriginFilter) || !matchesOptional(coffee.RoastLevel, state.RoastFilter) {
+			continue
+		}
+		if containsAllergen(coffee.Allergens, state.ExcludeAllergens) {
+			continue
+		}
+
+		coffeeState := newCoffeesModel(coffee, c.settings)
+
+		if !state.MaxPricePerIngredient.IsNull() {
+			if coffeeState.PricePerIngredient.IsNull() || coffeeState.PricePerIngredient.ValueFloat64() > state.MaxPricePerIngredient.ValueFloat64() {
+				continue
+			}
+		}
+
+		// Coffees past the limit are only counted.
+		matched++
+		if !state.Limit.IsNull() && int64(matched) > state.Limit.ValueInt64() {
+			continue
 		}
 
-		for _, ingredient := range coffee.Ingredient {
-			coffeeState.Ingredients = append(coffeeState.Ingredients, coffeesIngredientsModel{
-				ID: types.Int64Value(int64(ingredient.ID)),
-			})
+		if !state.IncludeTeaser.IsNull() && !state.IncludeTeaser.ValueBool() {
+			coffeeState.Teaser = types.StringNull()
+		}
+
+		if state.RefreshImageURLs.ValueBool() {
+			image, err := c.client.RefreshImageURL(ctx, coffee.ID)
+			if err != nil {
+				resp.Diagnostics.AddWarning(
+					"Unable to Refresh HashiCups Coffee Image URL",
+					fmt.Sprintf("Could not refresh the image URL for coffee ID %d, keeping the catalog URL: %s", coffee.ID, err),
+				)
+			} else {
+				coffeeState.Image = types.StringValue(image)
+			}
+		}
+
+		ingredients := coffee.Ingredient
+		if state.EnrichIngredients.ValueBool() {
+			enriched, err := c.client.GetIngredients(ctx, coffee.ID)
+			if err != nil {
+				resp.Diagnostics.AddWarning(
+					"Unable to Read HashiCups Coffee Ingredients",
+					fmt.Sprintf("Could not read the ingredients of coffee ID %d, leaving their names out of all_ingredient_names: %s", coffee.ID, err),
+				)
+			} else {
+				ingredients = enriched
+			}
+		}
+		for _, ingredient := range ingredients {
+			hasIngredients = true
+			if ingredient.Name != "" {
+				ingredientNames[ingredient.Name] = true
+			}
 		}
 
 		state.Coffees = append(state.Coffees, coffeeState)
 	}
 
+	if len(state.Coffees) < matched {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("limit"),
+			"HashiCups Coffees Truncated",
+			fmt.Sprintf("Only the first %d of the %d coffees matching the filters were returned. Raise or remove the limit to read them all.", len(state.Coffees), matched),
+		)
+	}
+
+	if hasIngredients && len(ingredientNames) == 0 && !state.EnrichIngredients.ValueBool() {
+		resp.Diagnostics.AddWarning(
+			"HashiCups Ingredient Names Not Available",
+			"The coffee catalog does not include ingredient names, so all_ingredient_names is empty. Set enrich_ingredients to read them.",
+		)
+	}
+	names := make([]string, 0, len(ingredientNames))
+	for name := range ingredientNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	state.AllIngredientNames = []types.String{}
+	for _, name := range names {
+		state.AllIngredientNames = append(state.AllIngredientNames, types.StringValue(name))
+	}
+	state.AllIngredientNames = listOrNull(state.AllIngredientNames, c.settings.emptyListsAsNull)
+
+	state.setPriceAggregates()
+	state.Coffees = listOrNull(state.Coffees, c.settings.emptyListsAsNull)
+	state.CoffeesByID = nil
+	if state.Coffees != nil {
+		state.CoffeesByID = make(map[string]coffeesModel, len(state.Coffees))
+	}
+	for _, coffee := range state.Coffees {
+		state.CoffeesByID[strconv.FormatInt(coffee.ID.ValueInt64(), 10)] = coffee
+	}
 	state.ID = types.StringValue("placeholder")
+	state.FetchedAt = types.StringValue(now.UTC().Format(time.RFC3339))
 
 	// Set state
-	diags := resp.State.Set(ctx, &state)
+	diags = resp.State.Set(ctx, &state)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
 }
 
+// setPriceAggregates sets the average, minimum, and maximum price of the
+// coffees in the model, or nulls when there are none.
+func (m *coffeesDataSourceModel) setPriceAggregates() {
+	m.AveragePrice = types.Float64Null()
+	m.MinCatalogPrice = types.Float64Null()
+	m.MaxCatalogPrice = types.Float64Null()
+	if len(m.Coffees) == 0 {
+		return
+	}
+
+	var total float64
+	minPrice := m.Coffees[0].Price.ValueFloat64()
+	maxPrice := minPrice
+	for _, coffee := range m.Coffees {
+		price := coffee.Price.ValueFloat64()
+		total += price
+		minPrice = min(minPrice, price)
+		maxPrice = max(maxPrice, price)
+	}
+
+	m.AveragePrice = types.Float64Value(total / float64(len(m.Coffees)))
+	m.MinCatalogPrice = types.Float64Value(minPrice)
+	m.MaxCatalogPrice = types.Float64Value(maxPrice)
+}
+
+// mergeExtraHosts appends the coffees of each extra host whose ID has not
+// been seen yet. Failed hosts are reported as warnings, and an error is added
+// when no host, including the provider host, could be read.
+func (c *coffeesDataSource) mergeExtraHosts(ctx context.Context, coffees []Coffee, succeeded bool, hosts []string, diags *diag.Diagnostics) []Coffee {
+	seen := make(map[int]bool, len(coffees))
+	for _, coffee := range coffees {
+		seen[coffee.ID] = true
+	}
+
+	for _, host := range hosts {
+		hostCoffees, err := c.client.GetCoffeesFromHost(ctx, host)
+		if err != nil {
+			diags.AddWarning(
+				"Unable to Read HashiCups Coffees From Host",
+				fmt.Sprintf("Could not read coffees from %s, continuing with the remaining hosts: %s", host, err),
+			)
+			continue
+		}
+		succeeded = true
+
+		for _, coffee := range hostCoffees {
+			if seen[coffee.ID] {
+				continue
+			}
+			seen[coffee.ID] = true
+			coffees = append(coffees, coffee)
+		}
+	}
+
+	if !succeeded {
+		diags.AddError(
+			"Unable to Read HashiCups Coffees",
+			"Could not read coffees from any of the configured hosts.",
+		)
+	}
+
+	return coffees
+}
+
 func (c *coffeesDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
 	if request.ProviderData == nil {
 		return
 	}
 
-	c.client = request.ProviderData.(*Client)
+	data := request.ProviderData.(*providerData)
+	c.client = data.client
+	c.settings = data.settings
+}
+
+// newCoffeesModel maps an API coffee to its schema data.
+func newCoffeesModel(coffee Coffee, settings providerSettings) coffeesModel {
+	labels := make(map[string]attr.Value, len(coffee.Metadata))
+	for key, value := range coffee.Metadata {
+		labels[key] = types.StringValue(value)
+	}
+
+	model := coffeesModel{
+		ID:                 types.Int64Value(int64(coffee.ID)),
+		Name:               types.StringValue(coffee.Name),
+		Slug:               types.StringValue(coffeeSlug(coffee.Name)),
+		LocalizedName:      types.StringValue(coffee.Name),
+		Teaser:             types.StringValue(coffee.Teaser),
+		Description:        types.StringValue(coffee.Description),
+		Price:              types.Float64Value(coffee.Price),
+		DiscountPrice:      types.Float64Value(settings.discountPrice(coffee.Price)),
+		Image:              types.StringValue(coffee.Image),
+		AvailableFrom:      timeValue(coffee.AvailableFrom),
+		AvailableUntil:     timeValue(coffee.AvailableUntil),
+		Labels:             types.MapValueMust(types.StringType, labels),
+		Origin:             types.StringPointerValue(coffee.Origin),
+		RoastLevel:         types.StringPointerValue(coffee.RoastLevel),
+		Process:            types.StringPointerValue(coffee.Process),
+		PricePerIngredient: types.Float64Null(),
+		IsNew:              types.BoolValue(coffee.IsNewAt(settings.currentTime(), settings.newWindow)),
+	}
+
+	if coffee.LocalizedName != nil && *coffee.LocalizedName != "" {
+		model.LocalizedName = types.StringValue(*coffee.LocalizedName)
+	}
+	if len(coffee.Ingredient) > 0 {
+		model.PricePerIngredient = types.Float64Value(coffee.Price / float64(len(coffee.Ingredient)))
+	}
+
+	for _, ingredient := range coffee.Ingredient {
+		model.Ingredients = append(model.Ingredients, coffeesIngredientsModel{
+			ID: types.Int64Value(int64(ingredient.ID)),
+		})
+	}
+	model.Ingredients = listOrNull(model.Ingredients, settings.emptyListsAsNull)
+
+	if coffee.Allergens != nil {
+		model.Allergens = []types.String{}
+		for _, allergen := range coffee.Allergens {
+			model.Allergens = append(model.Allergens, types.StringValue(allergen))
+		}
+		model.Allergens = listOrNull(model.Allergens, settings.emptyListsAsNull)
+	}
+
+	return model
+}
+
+// coffeeSlug returns the URL-friendly form of a coffee name. Accented
+// letters lose their accents, other letters and digits are kept in lower
+// case, and runs of spaces, hyphens, and underscores become one hyphen.
+func coffeeSlug(name string) string {
+	var slug strings.Builder
+	separate := false
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Drop the accents split off by the decomposition.
+		case unicode.IsLetter(r) || unicode.IsNumber(r):
+			if separate && slug.Len() > 0 {
+				slug.WriteByte('-')
+			}
+			separate = false
+			slug.WriteRune(unicode.ToLower(r))
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			separate = true
+		}
+	}
+
+	return norm.NFC.String(slug.String())
+}
+
+// catalogChecksum returns the hex encoded SHA-256 of the coffees sorted by
+// ID, so the checksum does not depend on the order the API returns them in.
+func catalogChecksum(coffees []Coffee) (string, error) {
+	sorted := make([]Coffee, len(coffees))
+	copy(sorted, coffees)
+	sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].ID < sorted[j].ID })
+
+	data, err := json.Marshal(sorted)
+	if err != nil {
+		return "", err
+	}
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// parseLabelSelector parses comma-separated key=value pairs. An empty
+// selector matches every coffee.
+func parseLabelSelector(selector string) (map[string]string, error) {
+	labels := map[string]string{}
+	if strings.TrimSpace(selector) == "" {
+		return labels, nil
+	}
+
+	for _, pair := range strings.Split(selector, ",") {
+		key, value, ok := strings.Cut(pair, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" {
+			return nil, fmt.Errorf("expected comma-separated key=value pairs, got %q", pair)
+		}
+		labels[key] = strings.TrimSpace(value)
+	}
+
+	return labels, nil
+}
+
+// matchesLabels reports whether labels contains every pair in selector.
+func matchesLabels(labels, selector map[string]string) bool {
+	for key, value := range selector {
+		if actual, ok := labels[key]; !ok || actual != value {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesOptional reports whether value equals filter, ignoring case. A null
+// filter matches everything and a missing value matches no filter.
+func matchesOptional(value *string, filter types.String) bool {
+	if filter.IsNull() {
+		return true
+	}
+
+	return value != nil && strings.EqualFold(*value, filter.ValueString())
+}
+
+// containsAllergen reports whether allergens includes any of excluded,
+// ignoring case.
+func containsAllergen(allergens []string, excluded []types.String) bool {
+	for _, allergen := range allergens {
+		for _, exclude := range excluded {
+			if strings.EqualFold(allergen, exclude.ValueString()) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// listOrNull returns items, replacing an empty list with nil, which maps to
+// null, when asNull is set and with a non-nil empty slice otherwise.
+func listOrNull[T any](items []T, asNull bool) []T {
+	if len(items) > 0 {
+		return items
+	}
+	if asNull {
+		return nil
+	}
+
+	return []T{}
+}
+
+// timeValue returns t formatted as RFC3339, or null when t is nil.
+func timeValue(t *time.Time) types.String {
+	if t == nil {
+		return types.StringNull()
+	}
+
+	return types.StringValue(t.Format(time.RFC3339))
 }
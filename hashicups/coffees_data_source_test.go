@@ -1,8 +1,20 @@
 package hashicups
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -32,3 +44,1044 @@ func TestAccCoffeesDataSource(t *testing.T) {
 		},
 	})
 }
+
+func TestCoffeesDataSourceReadRetry(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) <= 2 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","price":200}]`))
+	})
+	client.RetryMax = 3
+	client.RetryWaitMin = time.Millisecond
+	client.RetryWaitMax = 5 * time.Millisecond
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client}, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if calls := atomic.LoadInt32(&calls); calls != 3 {
+		t.Errorf("expected 3 requests, got %d", calls)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if len(state.Coffees) != 1 || state.Coffees[0].Name.ValueString() != "HCP Aeropress" {
+		t.Errorf("unexpected coffees: %+v", state.Coffees)
+	}
+}
+
+func TestCoffeesDataSourceReadOnly(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+	})
+
+	d := &coffeesDataSource{}
+	d.Configure(context.Background(), datasource.ConfigureRequest{
+		ProviderData: &providerData{client: client, settings: providerSettings{readOnly: true}},
+	}, &datasource.ConfigureResponse{})
+
+	resp := readTestDataSource(t, d, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+}
+
+func TestCoffeesDataSourceRefreshImageURLs(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees":
+			_, _ = w.Write([]byte(`[{"id":1,"image":"/stale-1.png"},{"id":2,"image":"/stale-2.png"}]`))
+		case "/coffees/1/image":
+			_, _ = w.Write([]byte(`{"url":"/fresh-1.png?sig=abc"}`))
+		default:
+			w.WriteHeader(http.StatusInternalServerError)
+		}
+	})
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client}, &coffeesDataSourceModel{
+		RefreshImageURLs: types.BoolValue(true),
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected one refresh warning, got %v", resp.Diagnostics)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if got := state.Coffees[0].Image.ValueString(); got != "/fresh-1.png?sig=abc" {
+		t.Errorf("expected refreshed image URL, got %q", got)
+	}
+	if got := state.Coffees[1].Image.ValueString(); got != "/stale-2.png" {
+		t.Errorf("expected stale image URL to be kept, got %q", got)
+	}
+}
+
+func TestCoffeesDataSourceAvailableNow(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"Summer Brew","available_from":"2024-06-01T00:00:00Z","available_until":"2024-09-01T00:00:00Z"},
+			{"id":2,"name":"Winter Brew","available_from":"2024-12-01T00:00:00Z","available_until":"2025-03-01T00:00:00Z"},
+			{"id":3,"name":"House Blend"}
+		]`))
+	})
+	settings := providerSettings{
+		now: func() time.Time { return time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC) },
+	}
+
+	tests := map[string]struct {
+		availableNow types.Bool
+		expectedIDs  []int64
+	}{
+		"unfiltered": {
+			availableNow: types.BoolNull(),
+			expectedIDs:  []int64{1, 2, 3},
+		},
+		"available now": {
+			availableNow: types.BoolValue(true),
+			expectedIDs:  []int64{1, 3},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, &coffeesDataSource{client: client, settings: settings}, &coffeesDataSourceModel{
+				AvailableNow: test.availableNow,
+			})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+		})
+	}
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client, settings: settings}, nil)
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if got := state.Coffees[0].AvailableFrom.ValueString(); got != "2024-06-01T00:00:00Z" {
+		t.Errorf("unexpected available_from: %q", got)
+	}
+	if !state.Coffees[2].AvailableFrom.IsNull() || !state.Coffees[2].AvailableUntil.IsNull() {
+		t.Errorf("expected null availability window for always-available coffee")
+	}
+}
+
+func TestCoffeesDataSourceIsNew(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"Fresh Roast","created_at":"2024-07-14T12:00:00Z"},
+			{"id":2,"name":"Classic Roast","created_at":"2024-01-01T00:00:00Z"},
+			{"id":3,"name":"House Blend"}
+		]`))
+	})
+	settings := providerSettings{
+		newWindow: 72 * time.Hour,
+		now:       func() time.Time { return time.Date(2024, 7, 15, 12, 0, 0, 0, time.UTC) },
+	}
+
+	tests := map[string]struct {
+		newOnly       types.Bool
+		expectedIDs   []int64
+		expectedIsNew []bool
+	}{
+		"unfiltered": {
+			newOnly:       types.BoolNull(),
+			expectedIDs:   []int64{1, 2, 3},
+			expectedIsNew: []bool{true, false, false},
+		},
+		"new only": {
+			newOnly:       types.BoolValue(true),
+			expectedIDs:   []int64{1},
+			expectedIsNew: []bool{true},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, &coffeesDataSource{client: client, settings: settings}, &coffeesDataSourceModel{
+				NewOnly: test.newOnly,
+			})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			var isNew []bool
+			for _, coffee := range state.Coffees {
+				isNew = append(isNew, coffee.IsNew.ValueBool())
+			}
+			if !reflect.DeepEqual(isNew, test.expectedIsNew) {
+				t.Errorf("expected is_new %v, got %v", test.expectedIsNew, isNew)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceAllIngredientNames(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees":
+			_, _ = w.Write([]byte(`[
+				{"id":1,"name":"Latte","ingredients":[{"ingredient_id":1},{"ingredient_id":2}]},
+				{"id":2,"name":"Cortado","ingredients":[{"ingredient_id":1},{"ingredient_id":2}]},
+				{"id":3,"name":"Espresso","ingredients":[{"ingredient_id":1}]}
+			]`))
+		case "/coffees/1/ingredients", "/coffees/2/ingredients":
+			_, _ = w.Write([]byte(`[{"ingredient_id":1,"name":"Espresso"},{"ingredient_id":2,"name":"Steamed Milk"}]`))
+		case "/coffees/3/ingredients":
+			_, _ = w.Write([]byte(`[{"ingredient_id":1,"name":"Espresso"}]`))
+		}
+	})
+
+	tests := map[string]struct {
+		enrich        types.Bool
+		expectedNames []string
+		expectWarning bool
+	}{
+		"enriched": {
+			enrich:        types.BoolValue(true),
+			expectedNames: []string{"Espresso", "Steamed Milk"},
+		},
+		"not enriched": {
+			enrich:        types.BoolNull(),
+			expectedNames: []string{},
+			expectWarning: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, &coffeesDataSource{client: client}, &coffeesDataSourceModel{
+				EnrichIngredients: test.enrich,
+			})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.WarningsCount() == 1; got != test.expectWarning {
+				t.Errorf("expected warning %t, got %v", test.expectWarning, resp.Diagnostics)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			names := []string{}
+			for _, name := range state.AllIngredientNames {
+				names = append(names, name.ValueString())
+			}
+			if !reflect.DeepEqual(names, test.expectedNames) {
+				t.Errorf("expected all_ingredient_names %v, got %v", test.expectedNames, names)
+			}
+		})
+	}
+}
+
+// testCoffeeIDs returns the IDs of the coffees in the data source state.
+func testCoffeeIDs(t *testing.T, resp *datasource.ReadResponse) []int64 {
+	t.Helper()
+
+	var state coffeesDataSourceModel
+	if diags := resp.State.Get(context.Background(), &state); diags.HasError() {
+		t.Fatalf("unable to read state: %v", diags)
+	}
+
+	var ids []int64
+	for _, coffee := range state.Coffees {
+		ids = append(ids, coffee.ID.ValueInt64())
+	}
+
+	return ids
+}
+
+func TestCoffeesDataSourceLabels(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"Colombia Dark","metadata":{"origin":"colombia","roast":"dark"}},
+			{"id":2,"name":"Colombia Light","metadata":{"origin":"colombia","roast":"light"}},
+			{"id":3,"name":"House Blend"}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	resp := readTestDataSource(t, d, nil)
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+
+	expected := types.MapValueMust(types.StringType, map[string]attr.Value{
+		"origin": types.StringValue("colombia"),
+		"roast":  types.StringValue("dark"),
+	})
+	if !state.Coffees[0].Labels.Equal(expected) {
+		t.Errorf("expected labels %s, got %s", expected, state.Coffees[0].Labels)
+	}
+	if labels := state.Coffees[2].Labels; labels.IsNull() || len(labels.Elements()) != 0 {
+		t.Errorf("expected empty labels for unlabelled coffee, got %s", labels)
+	}
+
+	tests := map[string]struct {
+		selector    string
+		expectedIDs []int64
+		expectError bool
+	}{
+		"single label": {
+			selector:    "origin=colombia",
+			expectedIDs: []int64{1, 2},
+		},
+		"multiple labels": {
+			selector:    "origin=colombia, roast=light",
+			expectedIDs: []int64{2},
+		},
+		"no match": {
+			selector: "origin=kenya",
+		},
+		"invalid": {
+			selector:    "origin",
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, d, &coffeesDataSourceModel{
+				LabelSelector: types.StringValue(test.selector),
+			})
+			if test.expectError {
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceOriginAndRoast(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"origin":"Colombia","roast_level":"dark","process":"washed"},
+			{"id":2,"origin":"Ethiopia","roast_level":"light","process":"natural"},
+			{"id":3,"origin":"Colombia","roast_level":"light"},
+			{"id":4}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	resp := readTestDataSource(t, d, nil)
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+
+	first := state.Coffees[0]
+	if first.Origin.ValueString() != "Colombia" || first.RoastLevel.ValueString() != "dark" || first.Process.ValueString() != "washed" {
+		t.Errorf("unexpected bean details: %s, %s, %s", first.Origin, first.RoastLevel, first.Process)
+	}
+	if last := state.Coffees[3]; !last.Origin.IsNull() || !last.RoastLevel.IsNull() || !last.Process.IsNull() {
+		t.Errorf("expected null bean details for coffee without them")
+	}
+
+	tests := map[string]struct {
+		config      coffeesDataSourceModel
+		expectedIDs []int64
+	}{
+		"origin filter": {
+			config:      coffeesDataSourceModel{OriginFilter: types.StringValue("colombia")},
+			expectedIDs: []int64{1, 3},
+		},
+		"roast filter": {
+			config:      coffeesDataSourceModel{RoastFilter: types.StringValue("LIGHT")},
+			expectedIDs: []int64{2, 3},
+		},
+		"both filters": {
+			config:      coffeesDataSourceModel{OriginFilter: types.StringValue("Colombia"), RoastFilter: types.StringValue("light")},
+			expectedIDs: []int64{3},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, d, &test.config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceEmptyListsAsNull(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"ingredients":[{"ingredient_id":6}]},{"id":2},{"id":3,"ingredients":null}]`))
+	})
+
+	tests := map[string]struct {
+		emptyListsAsNull bool
+		expectNull       bool
+	}{
+		"empty":         {},
+		"empty as null": {emptyListsAsNull: true, expectNull: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &coffeesDataSource{client: client, settings: providerSettings{emptyListsAsNull: test.emptyListsAsNull}}
+
+			resp := readTestDataSource(t, d, nil)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var ingredients types.List
+			// Coffee 2 omits its ingredients and coffee 3 sends them as null.
+			for _, i := range []int{1, 2} {
+				diags := resp.State.GetAttribute(context.Background(), path.Root("coffees").AtListIndex(i).AtName("ingredients"), &ingredients)
+				if diags.HasError() {
+					t.Fatalf("unable to read ingredients: %v", diags)
+				}
+				if ingredients.IsNull() != test.expectNull {
+					t.Errorf("coffee %d: expected null %t, got %s", i+1, test.expectNull, ingredients)
+				}
+				if !test.expectNull && len(ingredients.Elements()) != 0 {
+					t.Errorf("coffee %d: expected empty ingredients, got %s", i+1, ingredients)
+				}
+			}
+
+			diags := resp.State.GetAttribute(context.Background(), path.Root("coffees").AtListIndex(0).AtName("ingredients"), &ingredients)
+			if diags.HasError() || len(ingredients.Elements()) != 1 {
+				t.Errorf("expected one ingredient for coffee 1, got %s", ingredients)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceEmptyCatalogAsNull(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	tests := map[string]struct {
+		emptyListsAsNull bool
+		expectNull       bool
+	}{
+		"empty":         {},
+		"empty as null": {emptyListsAsNull: true, expectNull: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			d := &coffeesDataSource{client: client, settings: providerSettings{emptyListsAsNull: test.emptyListsAsNull}}
+
+			resp := readTestDataSource(t, d, nil)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var coffees types.List
+			diags := resp.State.GetAttribute(context.Background(), path.Root("coffees"), &coffees)
+			if diags.HasError() {
+				t.Fatalf("unable to read coffees: %v", diags)
+			}
+			var coffeesByID types.Map
+			diags = resp.State.GetAttribute(context.Background(), path.Root("coffees_by_id"), &coffeesByID)
+			if diags.HasError() {
+				t.Fatalf("unable to read coffees_by_id: %v", diags)
+			}
+
+			if coffees.IsNull() != test.expectNull {
+				t.Errorf("expected coffees null %t, got %s", test.expectNull, coffees)
+			}
+			if coffeesByID.IsNull() != test.expectNull {
+				t.Errorf("expected coffees_by_id null %t, got %s", test.expectNull, coffeesByID)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceExtraHosts(t *testing.T) {
+	var primaryKey, otherKey string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		primaryKey = r.Header.Get("X-API-Key")
+		_, _ = w.Write([]byte(`[{"id":1,"name":"primary"},{"id":2,"name":"primary"}]`))
+	})
+	client.Authenticator = &APIKeyAuthenticator{Header: "X-API-Key", Key: "secret"}
+	other := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		otherKey = r.Header.Get("X-API-Key")
+		_, _ = w.Write([]byte(`[{"id":2,"name":"other"},{"id":3,"name":"other"}]`))
+	})
+	failing := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	d := &coffeesDataSource{client: client}
+
+	config := coffeesDataSourceModel{
+		ExtraHosts: []types.String{
+			types.StringValue(other.HostURL),
+			types.StringValue(failing.HostURL),
+		},
+	}
+
+	resp := readTestDataSource(t, d, &config)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Errorf("expected one warning for the failing host, got %v", resp.Diagnostics)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+
+	var got []string
+	for _, coffee := range state.Coffees {
+		got = append(got, fmt.Sprintf("%d:%s", coffee.ID.ValueInt64(), coffee.Name.ValueString()))
+	}
+	expected := []string{"1:primary", "2:primary", "3:other"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected coffees %v, got %v", expected, got)
+	}
+	if primaryKey != "secret" || otherKey != "" {
+		t.Errorf("expected the API key to be sent only to the provider host, got %q and %q", primaryKey, otherKey)
+	}
+}
+
+func TestCoffeesDataSourceCoffeesByID(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"},{"id":3,"name":"Nomadicano"}]`))
+	})
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client}, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+
+	got := map[string]string{}
+	for key, coffee := range state.CoffeesByID {
+		got[key] = coffee.Name.ValueString()
+	}
+	expected := map[string]string{"1": "HCP Aeropress", "3": "Nomadicano"}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected coffees_by_id %v, got %v", expected, got)
+	}
+}
+
+func TestCoffeesDataSourceFetchedAt(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+	settings := providerSettings{
+		now: func() time.Time { return time.Date(2024, 7, 15, 14, 30, 0, 0, time.FixedZone("CEST", 2*60*60)) },
+	}
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client, settings: settings}, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if got := state.FetchedAt.ValueString(); got != "2024-07-15T12:30:00Z" {
+		t.Errorf("expected fetched_at 2024-07-15T12:30:00Z, got %q", got)
+	}
+}
+
+func TestCoffeesDataSourceDeprecationWarning(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", "@1735689600")
+		w.Header().Set("Sunset", "Wed, 31 Dec 2025 23:59:59 GMT")
+		_, _ = w.Write([]byte(`[]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	var warnings diag.Diagnostics
+	for i := 0; i < 3; i++ {
+		resp := readTestDataSource(t, d, nil)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+		warnings.Append(resp.Diagnostics.Warnings()...)
+	}
+
+	if len(warnings) != 1 {
+		t.Fatalf("expected a single deprecation warning, got %v", warnings)
+	}
+	if detail := warnings[0].Detail(); !strings.Contains(detail, "GET /coffees") || !strings.Contains(detail, "Wed, 31 Dec 2025 23:59:59 GMT") {
+		t.Errorf("unexpected warning detail: %s", detail)
+	}
+}
+
+func TestCoffeesDataSourceLimit(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"price":100},{"id":2,"price":200},{"id":3,"price":300},{"id":4,"price":400}]`))
+	})
+
+	tests := map[string]struct {
+		config        *coffeesDataSourceModel
+		expectedIDs   []int64
+		expectWarning string
+	}{
+		"truncated": {
+			config:        &coffeesDataSourceModel{Limit: types.Int64Value(2)},
+			expectedIDs:   []int64{1, 2},
+			expectWarning: "first 2 of the 4 coffees",
+		},
+		"filtered then truncated": {
+			config:        &coffeesDataSourceModel{Limit: types.Int64Value(1), MinPrice: types.Float64Value(250)},
+			expectedIDs:   []int64{3},
+			expectWarning: "first 1 of the 2 coffees",
+		},
+		"within limit": {
+			config:      &coffeesDataSourceModel{Limit: types.Int64Value(4)},
+			expectedIDs: []int64{1, 2, 3, 4},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, &coffeesDataSource{client: client}, test.config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, test.expectedIDs) {
+				t.Errorf("expected coffee IDs %v, got %v", test.expectedIDs, ids)
+			}
+
+			warnings := resp.Diagnostics.Warnings()
+			if test.expectWarning == "" {
+				if len(warnings) != 0 {
+					t.Errorf("unexpected warnings: %v", warnings)
+				}
+				return
+			}
+			if len(warnings) != 1 || !strings.Contains(warnings[0].Detail(), test.expectWarning) {
+				t.Errorf("expected a warning mentioning %q, got %v", test.expectWarning, warnings)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceDiscountPrice(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"price":200},{"id":2,"price":2.5}]`))
+	})
+
+	tests := map[string]struct {
+		settings       providerSettings
+		expectedPrices []float64
+	}{
+		"no promo":                {expectedPrices: []float64{200, 2.5}},
+		"25 percent":              {settings: providerSettings{promoPercent: 25}, expectedPrices: []float64{150, 1.88}},
+		"25 percent rounded down": {settings: providerSettings{promoPercent: 25, priceRounding: priceRoundingDown}, expectedPrices: []float64{150, 1.87}},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, &coffeesDataSource{client: client, settings: test.settings}, nil)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			var prices []float64
+			for _, coffee := range state.Coffees {
+				prices = append(prices, coffee.DiscountPrice.ValueFloat64())
+			}
+			if !reflect.DeepEqual(prices, test.expectedPrices) {
+				t.Errorf("expected discount prices %v, got %v", test.expectedPrices, prices)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourcePricePerIngredient(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"price":200,"ingredients":[{"ingredient_id":1},{"ingredient_id":2}]},
+			{"id":2,"price":300,"ingredients":[{"ingredient_id":1}]},
+			{"id":3,"price":100}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	resp := readTestDataSource(t, d, nil)
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+
+	if got := state.Coffees[0].PricePerIngredient.ValueFloat64(); got != 100 {
+		t.Errorf("expected price per ingredient 100, got %v", got)
+	}
+	if got := state.Coffees[1].PricePerIngredient.ValueFloat64(); got != 300 {
+		t.Errorf("expected price per ingredient 300, got %v", got)
+	}
+	if !state.Coffees[2].PricePerIngredient.IsNull() {
+		t.Errorf("expected null price per ingredient without ingredients, got %s", state.Coffees[2].PricePerIngredient)
+	}
+
+	resp = readTestDataSource(t, d, &coffeesDataSourceModel{MaxPricePerIngredient: types.Float64Value(150)})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, []int64{1}) {
+		t.Errorf("expected coffee IDs [1], got %v", ids)
+	}
+}
+
+func TestCoffeesDataSourceCatalogChecksum(t *testing.T) {
+	checksum := func(t *testing.T, body string) string {
+		t.Helper()
+		client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte(body))
+		})
+
+		resp := readTestDataSource(t, &coffeesDataSource{client: client}, nil)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+
+		var state coffeesDataSourceModel
+		resp.State.Get(context.Background(), &state)
+
+		return state.CatalogChecksum.ValueString()
+	}
+
+	original := checksum(t, `[{"id":1,"name":"HCP Aeropress"},{"id":2,"name":"Packer Spiced Latte"}]`)
+	reordered := checksum(t, `[{"id":2,"name":"Packer Spiced Latte"},{"id":1,"name":"HCP Aeropress"}]`)
+	added := checksum(t, `[{"id":1,"name":"HCP Aeropress"},{"id":2,"name":"Packer Spiced Latte"},{"id":3,"name":"Vaulatte"}]`)
+
+	if original == "" {
+		t.Fatal("expected a checksum")
+	}
+	if reordered != original {
+		t.Errorf("expected checksum to be stable across reorders, got %s and %s", original, reordered)
+	}
+	if added == original {
+		t.Errorf("expected checksum to change when a coffee is added")
+	}
+}
+
+func TestCoffeesDataSourceLocalizedName(t *testing.T) {
+	var acceptLanguage string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		acceptLanguage = r.Header.Get("Accept-Language")
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HashiCup","localized_name":"Tasse Hashi"},{"id":2,"name":"Nomadicano"}]`))
+	})
+	client.AcceptLanguage = "fr-CH"
+
+	resp := readTestDataSource(t, &coffeesDataSource{client: client}, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	if acceptLanguage != "fr-CH" {
+		t.Errorf("expected Accept-Language fr-CH, got %q", acceptLanguage)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if got := state.Coffees[0].LocalizedName.ValueString(); got != "Tasse Hashi" {
+		t.Errorf("expected localized name, got %q", got)
+	}
+	if got := state.Coffees[1].LocalizedName.ValueString(); got != "Nomadicano" {
+		t.Errorf("expected fallback to name, got %q", got)
+	}
+}
+
+func TestCoffeesDataSourceNameAndPriceFilters(t *testing.T) {
+	config := &coffeesDataSourceModel{
+		NameFilter: types.StringValue("latte"),
+		MinPrice:   types.Float64Value(150),
+		MaxPrice:   types.Float64Value(250),
+	}
+
+	tests := map[string]struct {
+		features      string
+		expectedQuery string
+	}{
+		"server side": {
+			features:      `["coffee_filters"]`,
+			expectedQuery: "max_price=250&min_price=150&name=latte",
+		},
+		"client side fallback": {
+			features: `[]`,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var query string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				if r.URL.Path == "/capabilities" {
+					_, _ = fmt.Fprintf(w, `{"features":%s}`, test.features)
+					return
+				}
+
+				query = r.URL.RawQuery
+				if query != "" {
+					_, _ = w.Write([]byte(`[{"id":2,"name":"Vaulatte","price":200}]`))
+					return
+				}
+				_, _ = w.Write([]byte(`[
+					{"id":1,"name":"HCP Aeropress","price":200},
+					{"id":2,"name":"Vaulatte","price":200},
+					{"id":3,"name":"Packer Spiced Latte","price":350}
+				]`))
+			})
+			client.capabilities = nil
+
+			resp := readTestDataSource(t, &coffeesDataSource{client: client}, config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if query != test.expectedQuery {
+				t.Errorf("expected query %q, got %q", test.expectedQuery, query)
+			}
+			if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, []int64{2}) {
+				t.Errorf("expected coffee IDs [2], got %v", ids)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceNameFilter(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"HCP Aeropress"},
+			{"id":2,"name":"Vaulatte"},
+			{"id":3,"name":"Packer Spiced Latte"}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	tests := map[string]struct {
+		nameFilter  types.String
+		expectNames []string
+	}{
+		"null":             {nameFilter: types.StringNull(), expectNames: []string{"HCP Aeropress", "Vaulatte", "Packer Spiced Latte"}},
+		"ignores case":     {nameFilter: types.StringValue("LATTE"), expectNames: []string{"Vaulatte", "Packer Spiced Latte"}},
+		"matches mid-word": {nameFilter: types.StringValue("eropr"), expectNames: []string{"HCP Aeropress"}},
+		"no match":         {nameFilter: types.StringValue("mocha"), expectNames: nil},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, d, &coffeesDataSourceModel{NameFilter: test.nameFilter})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			var names []string
+			for _, coffee := range state.Coffees {
+				names = append(names, coffee.Name.ValueString())
+			}
+			if len(names) != len(test.expectNames) || !reflect.DeepEqual(names, test.expectNames) {
+				t.Errorf("expected %d coffees %v, got %v", len(test.expectNames), test.expectNames, names)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceAllergens(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"HCP Aeropress","allergens":["milk","soy"]},
+			{"id":2,"name":"Nomadicano","allergens":[]},
+			{"id":3,"name":"Vaulatte"}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	resp := readTestDataSource(t, d, nil)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state coffeesDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	expected := []types.String{types.StringValue("milk"), types.StringValue("soy")}
+	if got := state.Coffees[0].Allergens; !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected allergens %v, got %v", expected, got)
+	}
+	if got := state.Coffees[1].Allergens; got == nil || len(got) != 0 {
+		t.Errorf("expected empty allergens, got %v", got)
+	}
+	if got := state.Coffees[2].Allergens; got != nil {
+		t.Errorf("expected null allergens without allergen data, got %v", got)
+	}
+
+	resp = readTestDataSource(t, d, &coffeesDataSourceModel{
+		ExcludeAllergens: []types.String{types.StringValue("Milk")},
+	})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if ids := testCoffeeIDs(t, resp); !reflect.DeepEqual(ids, []int64{2, 3}) {
+		t.Errorf("expected coffee IDs [2 3], got %v", ids)
+	}
+}
+
+func TestCoffeesDataSourceIncludeTeaser(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","teaser":"Automation in a cup"}]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	tests := map[string]struct {
+		includeTeaser types.Bool
+		expectTeaser  types.String
+	}{
+		"default": {includeTeaser: types.BoolNull(), expectTeaser: types.StringValue("Automation in a cup")},
+		"true":    {includeTeaser: types.BoolValue(true), expectTeaser: types.StringValue("Automation in a cup")},
+		"false":   {includeTeaser: types.BoolValue(false), expectTeaser: types.StringNull()},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := readTestDataSource(t, d, &coffeesDataSourceModel{IncludeTeaser: test.includeTeaser})
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			if got := state.Coffees[0].Teaser; !got.Equal(test.expectTeaser) {
+				t.Errorf("expected teaser %s, got %s", test.expectTeaser, got)
+			}
+			if got := state.CoffeesByID["1"].Teaser; !got.Equal(test.expectTeaser) {
+				t.Errorf("expected coffees_by_id teaser %s, got %s", test.expectTeaser, got)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourceCacheKey(t *testing.T) {
+	var requests int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","price":200},{"id":2,"name":"Vaulatte","price":150}]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	read := func(config *coffeesDataSourceModel) []int64 {
+		t.Helper()
+		resp := readTestDataSource(t, d, config)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+		return testCoffeeIDs(t, resp)
+	}
+
+	if ids := read(&coffeesDataSourceModel{CacheKey: types.StringValue("menu")}); !reflect.DeepEqual(ids, []int64{1, 2}) {
+		t.Errorf("expected coffee IDs [1 2], got %v", ids)
+	}
+	// A second reference with the same key reuses the catalog but applies
+	// its own filters.
+	if ids := read(&coffeesDataSourceModel{CacheKey: types.StringValue("menu"), MaxPrice: types.Float64Value(150)}); !reflect.DeepEqual(ids, []int64{2}) {
+		t.Errorf("expected coffee IDs [2], got %v", ids)
+	}
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("expected one catalog request for a shared cache key, got %d", requests)
+	}
+
+	read(&coffeesDataSourceModel{CacheKey: types.StringValue("other")})
+	read(&coffeesDataSourceModel{})
+	if requests := atomic.LoadInt32(&requests); requests != 3 {
+		t.Errorf("expected a request for a new cache key and one without a key, got %d requests in total", requests)
+	}
+}
+
+func TestCoffeeSlug(t *testing.T) {
+	tests := map[string]string{
+		"HCP Aeropress":            "hcp-aeropress",
+		"  Packer   Spiced Latte ": "packer-spiced-latte",
+		"Nomad's Café Crème!":      "nomads-cafe-creme",
+		"Terraform & Tea, v2.0":    "terraform-tea-v20",
+		"cold_brew--extra":         "cold-brew-extra",
+		"Größe Kaffee":             "große-kaffee",
+		"抹茶 Latte":                 "抹茶-latte",
+		"Cafe\u0301 au lait":       "cafe-au-lait",
+		"!!!":                      "",
+	}
+
+	for name, expected := range tests {
+		t.Run(name, func(t *testing.T) {
+			if got := coffeeSlug(name); got != expected {
+				t.Errorf("expected slug %q, got %q", expected, got)
+			}
+		})
+	}
+}
+
+func TestCoffeesDataSourcePriceAggregates(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[
+			{"id":1,"name":"HCP Aeropress","price":200},
+			{"id":2,"name":"Vaulatte","price":150},
+			{"id":3,"name":"Packer Spiced Latte","price":350}
+		]`))
+	})
+	d := &coffeesDataSource{client: client}
+
+	tests := map[string]struct {
+		config                              *coffeesDataSourceModel
+		expectAverage, expectMin, expectMax types.Float64
+	}{
+		"all coffees": {
+			expectAverage: types.Float64Value(700.0 / 3),
+			expectMin:     types.Float64Value(150),
+			expectMax:     types.Float64Value(350),
+		},
+		"after filtering": {
+			config:        &coffeesDataSourceModel{MaxPrice: types.Float64Value(200)},
+			expectAverage: types.Float64Value(175),
+			expectMin:     types.Float64Value(150),
+			expectMax:     types.Float64Value(200),
+		},
+		"no coffees": {
+			config:        &coffeesDataSourceModel{NameFilter: types.StringValue("mocha")},
+			expectAverage: types.Float64Null(),
+			expectMin:     types.Float64Null(),
+			expectMax:     types.Float64Null(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var config any
+			if test.config != nil {
+				config = test.config
+			}
+			resp := readTestDataSource(t, d, config)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var state coffeesDataSourceModel
+			resp.State.Get(context.Background(), &state)
+			if !state.AveragePrice.Equal(test.expectAverage) || !state.MinCatalogPrice.Equal(test.expectMin) || !state.MaxCatalogPrice.Equal(test.expectMax) {
+				t.Errorf("expected average %s, min %s, max %s, got %s, %s, %s",
+					test.expectAverage, test.expectMin, test.expectMax, state.AveragePrice, state.MinCatalogPrice, state.MaxCatalogPrice)
+			}
+		})
+	}
+}
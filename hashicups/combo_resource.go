@@ -0,0 +1,286 @@
+package hashicups
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &comboResource{}
+	_ resource.ResourceWithConfigure   = &comboResource{}
+	_ resource.ResourceWithImportState = &comboResource{}
+	_ resource.ResourceWithModifyPlan  = &comboResource{}
+)
+
+type comboResource struct {
+	client   *Client
+	settings providerSettings
+	catalog  *coffeeCatalog
+}
+
+// comboResourceModel maps the resource schema data.
+type comboResourceModel struct {
+	ID        types.String  `tfsdk:"id"`
+	Name      types.String  `tfsdk:"name"`
+	CoffeeIDs types.List    `tfsdk:"coffee_ids"`
+	Price     types.Float64 `tfsdk:"price"`
+}
+
+func NewComboResource() resource.Resource {
+	return &comboResource{}
+}
+
+func (r *comboResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_combo"
+}
+
+// Schema defines the schema for the resource.
+func (r *comboResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Manages a combo, a named bundle of coffees sold at a combo price.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Numeric identifier of the combo.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Product name of the combo.",
+			},
+			"coffee_ids": schema.ListAttribute{
+				ElementType: types.Int64Type,
+				Required:    true,
+				Description: "Numeric identifiers of the coffees in the combo.",
+			},
+			"price": schema.Float64Attribute{
+				Required:    true,
+				Description: "Price of the whole combo.",
+			},
+		},
+	}
+}
+
+// ModifyPlan checks that every coffee in the combo exists in the catalog.
+func (r *comboResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	// Nothing to check when the resource is being destroyed or the provider
+	// is not yet configured.
+	if request.Plan.Raw.IsNull() || r.client == nil {
+		return
+	}
+
+	var coffeeIDs types.List
+	diags := request.Plan.GetAttribute(ctx, path.Root("coffee_ids"), &coffeeIDs)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() || coffeeIDs.IsUnknown() || coffeeIDs.IsNull() {
+		return
+	}
+
+	var ids []types.Int64
+	diags = coffeeIDs.ElementsAs(ctx, &ids, false)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if r.catalog == nil {
+		r.catalog = &coffeeCatalog{client: r.client}
+	}
+	coffees, err := r.catalog.coffeesByID(ctx)
+	if err != nil {
+		response.Diagnostics.AddWarning(
+			"Unable to Check HashiCups Combo Coffees",
+			"Could not read the coffee catalog, skipping the coffee ID check: "+err.Error(),
+		)
+		return
+	}
+
+	for i, id := range ids {
+		if _, ok := coffees[id.ValueInt64()]; id.IsUnknown() || ok {
+			continue
+		}
+
+		response.Diagnostics.AddAttributeError(
+			path.Root("coffee_ids").AtListIndex(i),
+			"Unknown HashiCups Coffee",
+			fmt.Sprintf("Coffee ID %d does not exist in the HashiCups catalog.", id.ValueInt64()),
+		)
+	}
+}
+
+func (r *comboResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "create the combo")
+		return
+	}
+
+	var plan comboResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	combo, diags := plan.toCombo(ctx)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateCombo(ctx, combo)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Creating HashiCups Combo",
+			"Could not create combo, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = plan.fromCombo(ctx, created)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *comboResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	var state comboResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	combo, err := r.client.GetCombo(ctx, state.ID.ValueString())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Reading HashiCups Combo",
+			"Could not read HashiCups combo ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	diags = state.fromCombo(ctx, combo)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, &state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *comboResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "update the combo")
+		return
+	}
+
+	var plan comboResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	combo, diags := plan.toCombo(ctx)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateCombo(ctx, plan.ID.ValueString(), combo)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Updating HashiCups Combo",
+			"Could not update combo, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	diags = plan.fromCombo(ctx, updated)
+	response.Diagnostics.Append(diags...)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *comboResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "delete the combo")
+		return
+	}
+
+	var state comboResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteCombo(ctx, state.ID.ValueString())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Deleting HashiCups Combo",
+			"Could not delete combo, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *comboResource) Configure(_ context.Context, request resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	r.client = data.client
+	r.settings = data.settings
+	r.catalog = data.catalog
+}
+
+func (r *comboResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), request, response)
+}
+
+// toCombo builds the API request body from the model.
+func (m comboResourceModel) toCombo(ctx context.Context) (Combo, diag.Diagnostics) {
+	var coffeeIDs []int
+	diags := m.CoffeeIDs.ElementsAs(ctx, &coffeeIDs, false)
+
+	return Combo{
+		Name:      m.Name.ValueString(),
+		CoffeeIDs: coffeeIDs,
+		Price:     m.Price.ValueFloat64(),
+	}, diags
+}
+
+// fromCombo maps an API combo onto the model.
+func (m *comboResourceModel) fromCombo(ctx context.Context, combo *Combo) diag.Diagnostics {
+	coffeeIDs, diags := types.ListValueFrom(ctx, types.Int64Type, combo.CoffeeIDs)
+
+	m.ID = types.StringValue(strconv.Itoa(combo.ID))
+	m.Name = types.StringValue(combo.Name)
+	m.CoffeeIDs = coffeeIDs
+	m.Price = types.Float64Value(combo.Price)
+
+	return diags
+}
@@ -0,0 +1,107 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newTestComboClient returns a client backed by an in-memory combo API.
+func newTestComboClient(t *testing.T) *Client {
+	t.Helper()
+
+	combos := newTestCRUDHandler("/combos", "Deleted combo", func(combo *Combo, id int) { combo.ID = id })
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/coffees" {
+			_, _ = w.Write([]byte(`[{"id":1},{"id":2},{"id":3}]`))
+			return
+		}
+		combos(w, r)
+	})
+}
+
+func TestComboResourceCRUD(t *testing.T) {
+	planned := comboResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("Breakfast"),
+		CoffeeIDs: types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(1), types.Int64Value(2)}),
+		Price:     types.Float64Value(500),
+	}
+	coffeeIDs := types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(3)})
+
+	// Update member coffees
+	created, read, updated := runTestResourceCRUD(t, &comboResource{client: newTestComboClient(t)}, planned, func(m comboResourceModel) comboResourceModel {
+		m.CoffeeIDs = coffeeIDs
+		return m
+	})
+
+	if created.ID.ValueString() != "1" {
+		t.Fatalf("expected combo ID 1, got %s", created.ID)
+	}
+	if !read.CoffeeIDs.Equal(planned.CoffeeIDs) || read.Name.ValueString() != "Breakfast" {
+		t.Errorf("unexpected combo after read: %+v", read)
+	}
+	if !updated.CoffeeIDs.Equal(coffeeIDs) {
+		t.Errorf("expected coffee IDs %s, got %s", coffeeIDs, updated.CoffeeIDs)
+	}
+}
+
+func TestComboResourceModifyPlanUnknownCoffee(t *testing.T) {
+	ctx := context.Background()
+	r := &comboResource{client: newTestComboClient(t)}
+	s := testResourceSchema(t, r)
+
+	planned := comboResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("Breakfast"),
+		CoffeeIDs: types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(1), types.Int64Value(42)}),
+		Price:     types.Float64Value(500),
+	}
+
+	plan := testPlan(t, s, &planned)
+	resp := &resource.ModifyPlanResponse{Plan: plan}
+	r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: testState(t, s, nil)}, resp)
+
+	if resp.Diagnostics.ErrorsCount() != 1 || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), "Coffee ID 42") {
+		t.Errorf("expected an error naming coffee ID 42, got %v", resp.Diagnostics)
+	}
+}
+
+func TestComboResourceModifyPlanSharedCatalog(t *testing.T) {
+	ctx := context.Background()
+	var catalogReads int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		catalogReads++
+		_, _ = w.Write([]byte(`[{"id":1},{"id":2}]`))
+	})
+	catalog := &coffeeCatalog{client: client}
+	if _, err := catalog.coffeeIDs(ctx); err != nil {
+		t.Fatalf("unable to read catalog: %s", err)
+	}
+
+	r := &comboResource{client: client, catalog: catalog}
+	s := testResourceSchema(t, r)
+	plan := testPlan(t, s, &comboResourceModel{
+		ID:        types.StringUnknown(),
+		Name:      types.StringValue("Breakfast"),
+		CoffeeIDs: types.ListValueMust(types.Int64Type, []attr.Value{types.Int64Value(1), types.Int64Value(2)}),
+		Price:     types.Float64Value(500),
+	})
+
+	// The catalog already read for other resources is reused.
+	for i := 0; i < 2; i++ {
+		resp := &resource.ModifyPlanResponse{Plan: plan}
+		r.ModifyPlan(ctx, resource.ModifyPlanRequest{Plan: plan, State: testState(t, s, nil)}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+	}
+	if catalogReads != 1 {
+		t.Errorf("expected 1 catalog read, got %d", catalogReads)
+	}
+}
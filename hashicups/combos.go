@@ -0,0 +1,101 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetCombo - Returns a specific combo
+func (c *Client) GetCombo(ctx context.Context, comboID string) (*Combo, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/combos/%s", c.baseURL(), comboID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	combo := Combo{}
+	err = c.decode(body, &combo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &combo, nil
+}
+
+// CreateCombo - Create new combo
+func (c *Client) CreateCombo(ctx context.Context, combo Combo) (*Combo, error) {
+	rb, err := c.encode(combo)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/combos", c.baseURL()), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	newCombo := Combo{}
+	err = c.decode(body, &newCombo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newCombo, nil
+}
+
+// UpdateCombo - Updates a combo
+func (c *Client) UpdateCombo(ctx context.Context, comboID string, combo Combo) (*Combo, error) {
+	rb, err := c.encode(combo)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("%s/combos/%s", c.baseURL(), comboID), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedCombo := Combo{}
+	err = c.decode(body, &updatedCombo)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedCombo, nil
+}
+
+// DeleteCombo - Deletes a combo
+func (c *Client) DeleteCombo(ctx context.Context, comboID string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/combos/%s", c.baseURL(), comboID), nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > 0 && string(body) != "Deleted combo" {
+		return errors.New(string(body))
+	}
+
+	return nil
+}
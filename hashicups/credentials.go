@@ -0,0 +1,87 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"net/http"
+)
+
+// CredentialProvider supplies the tokens requests are authenticated with,
+// for tokens issued by an external source such as Vault instead of signing
+// in with a username and password.
+type CredentialProvider interface {
+	// Token returns a token for the Authorization header. It is called for
+	// the first request and again after the server rejects a token.
+	Token(ctx context.Context) (string, error)
+}
+
+// PasswordCredentials is the CredentialProvider that signs in with a
+// username and password, as NewClient does, so expired sign-in tokens are
+// replaced.
+type PasswordCredentials struct {
+	// Client sends the sign-in requests, usually the client the credentials
+	// are set on.
+	Client   *Client
+	Username string
+	Password string
+	// Bearer sends the token with the Bearer scheme instead of as the raw
+	// Authorization header.
+	Bearer bool
+}
+
+// Token signs in and returns the issued token.
+func (p *PasswordCredentials) Token(ctx context.Context) (string, error) {
+	ar, err := p.Client.signIn(ctx, AuthStruct{Username: p.Username, Password: p.Password})
+	if err != nil {
+		return "", err
+	}
+
+	return p.header(ar.Token), nil
+}
+
+// header returns the Authorization header value for token.
+func (p *PasswordCredentials) header(token string) string {
+	if p.Bearer {
+		return "Bearer " + token
+	}
+
+	return token
+}
+
+// withoutCredentialsKey marks the context of requests sent without
+// credentials or authentication, such as signing in to get them or reading
+// the public catalog of another host.
+type withoutCredentialsKey struct{}
+
+// applyCredentials sets the Authorization header of req to the token from
+// Credentials.
+func (c *Client) applyCredentials(req *http.Request) error {
+	token, err := c.credentialToken(req.Context(), "")
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", token)
+
+	return nil
+}
+
+// credentialToken returns the cached token, asking Credentials for a new one
+// when none is cached yet or the cached one is stale, a token the server
+// rejected. Requests rejected at the same time share one new token.
+func (c *Client) credentialToken(ctx context.Context, stale string) (string, error) {
+	c.credentialsMu.Lock()
+	defer c.credentialsMu.Unlock()
+
+	if c.credentialsToken == "" || c.credentialsToken == stale {
+		token, err := c.Credentials.Token(ctx)
+		if err != nil {
+			return "", err
+		}
+		if token == "" {
+			return "", errors.New("credential provider returned an empty token")
+		}
+		c.credentialsToken = token
+	}
+
+	return c.credentialsToken, nil
+}
@@ -0,0 +1,103 @@
+package hashicups
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &estimateOrderCostFunction{}
+
+// estimateOrderCostItemAttrTypes is the item shape accepted by
+// estimate_order_cost.
+var estimateOrderCostItemAttrTypes = map[string]attr.Type{
+	"coffee_id": types.Int64Type,
+	"quantity":  types.Int64Type,
+}
+
+func NewEstimateOrderCostFunction() function.Function {
+	return &estimateOrderCostFunction{}
+}
+
+type estimateOrderCostFunction struct {
+	// client looks up coffee prices. When nil, Run creates an
+	// unauthenticated client for HASHICUPS_HOST, since functions are called
+	// without a configured provider and the coffee catalog is public.
+	client *Client
+}
+
+// estimateOrderCostItemModel maps an item of the function argument.
+type estimateOrderCostItemModel struct {
+	CoffeeID types.Int64 `tfsdk:"coffee_id"`
+	Quantity types.Int64 `tfsdk:"quantity"`
+}
+
+func (f *estimateOrderCostFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "estimate_order_cost"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *estimateOrderCostFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary: "Estimate the cost of an order.",
+		Description: "Returns the total price of a list of coffee_id and quantity objects using the current coffee prices, " +
+			"without creating an order. The HashiCups API is read from the HASHICUPS_HOST environment variable.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "items",
+				Description: "Order items, a list of objects with coffee_id and quantity.",
+				ElementType: types.ObjectType{AttrTypes: estimateOrderCostItemAttrTypes},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+// Run looks up the coffee prices and sums the cost of each item.
+func (f *estimateOrderCostFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var items []estimateOrderCostItemModel
+
+	response.Error = request.Arguments.Get(ctx, &items)
+	if response.Error != nil {
+		return
+	}
+
+	client := f.client
+	if client == nil {
+		var host *string
+		if envHost := os.Getenv("HASHICUPS_HOST"); envHost != "" {
+			host = &envHost
+		}
+		client = NewClientWithAuthenticator(host, nil)
+	}
+
+	coffees, err := client.GetCoffees(ctx)
+	if err != nil {
+		response.Error = function.NewFuncError("Unable to read HashiCups coffee prices: " + err.Error())
+		return
+	}
+
+	prices := make(map[int64]float64, len(coffees))
+	for _, coffee := range coffees {
+		prices[int64(coffee.ID)] = coffee.Price
+	}
+
+	var total float64
+	for i, item := range items {
+		price, ok := prices[item.CoffeeID.ValueInt64()]
+		if !ok {
+			response.Error = function.NewArgumentFuncError(0,
+				fmt.Sprintf("Item %d: coffee ID %d does not exist in the HashiCups catalog.", i, item.CoffeeID.ValueInt64()))
+			return
+		}
+
+		total += price * float64(item.Quantity.ValueInt64())
+	}
+
+	response.Error = response.Result.Set(ctx, total)
+}
@@ -0,0 +1,55 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// testEstimateOrderCostItems builds an estimate_order_cost argument from
+// coffee ID and quantity pairs.
+func testEstimateOrderCostItems(t *testing.T, pairs ...int64) attr.Value {
+	t.Helper()
+
+	var items []estimateOrderCostItemModel
+	for i := 0; i < len(pairs); i += 2 {
+		items = append(items, estimateOrderCostItemModel{
+			CoffeeID: types.Int64Value(pairs[i]),
+			Quantity: types.Int64Value(pairs[i+1]),
+		})
+	}
+
+	value, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: estimateOrderCostItemAttrTypes}, items)
+	if diags.HasError() {
+		t.Fatalf("unable to build items: %v", diags)
+	}
+
+	return value
+}
+
+func TestEstimateOrderCostFunction(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"price":200},{"id":2,"price":150.5}]`))
+	})
+	f := &estimateOrderCostFunction{client: client}
+
+	t.Run("valid estimate", func(t *testing.T) {
+		resp := runTestFunction(t, f, testEstimateOrderCostItems(t, 1, 2, 2, 1))
+		if resp.Error != nil {
+			t.Fatalf("unexpected error: %s", resp.Error)
+		}
+		if got := resp.Result.Value().(types.Float64).ValueFloat64(); got != 550.5 {
+			t.Errorf("expected 550.5, got %v", got)
+		}
+	})
+
+	t.Run("unknown coffee", func(t *testing.T) {
+		resp := runTestFunction(t, f, testEstimateOrderCostItems(t, 1, 1, 9, 1))
+		if resp.Error == nil {
+			t.Fatal("expected error, got none")
+		}
+	})
+}
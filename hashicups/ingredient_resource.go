@@ -0,0 +1,245 @@
+package hashicups
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ resource.Resource                = &ingredientResource{}
+	_ resource.ResourceWithConfigure   = &ingredientResource{}
+	_ resource.ResourceWithImportState = &ingredientResource{}
+)
+
+// ingredientUnits are the units an ingredient quantity may be measured in.
+var ingredientUnits = []string{"g", "ml", "unit"}
+
+type ingredientResource struct {
+	client   *Client
+	settings providerSettings
+}
+
+// ingredientResourceModel maps the resource schema data.
+type ingredientResourceModel struct {
+	ID       types.String `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	Quantity types.Int64  `tfsdk:"quantity"`
+	Unit     types.String `tfsdk:"unit"`
+}
+
+func NewIngredientResource() resource.Resource {
+	return &ingredientResource{}
+}
+
+func (r *ingredientResource) Metadata(_ context.Context, request resource.MetadataRequest, response *resource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_ingredient"
+}
+
+// Schema defines the schema for the resource.
+func (r *ingredientResource) Schema(_ context.Context, _ resource.SchemaRequest, response *resource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Manages an ingredient of the HashiCups ingredient master list.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Numeric identifier of the ingredient.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the ingredient.",
+			},
+			"quantity": schema.Int64Attribute{
+				Required:    true,
+				Description: "Quantity of the ingredient, in unit. Must be positive.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"unit": schema.StringAttribute{
+				Required:    true,
+				Description: "Unit the quantity is measured in: `g`, `ml`, or `unit`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(ingredientUnits...),
+				},
+			},
+		},
+	}
+}
+
+func (r *ingredientResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "create the ingredient")
+		return
+	}
+
+	var plan ingredientResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	created, err := r.client.CreateIngredient(ctx, plan.toIngredient())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Creating HashiCups Ingredient",
+			"Could not create ingredient, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.fromIngredient(created)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ingredientResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	var state ingredientResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	ingredientID, diags := state.ingredientID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	ingredient, err := r.client.GetIngredient(ctx, ingredientID)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Reading HashiCups Ingredient",
+			"Could not read HashiCups ingredient ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	state.fromIngredient(ingredient)
+
+	diags = response.State.Set(ctx, &state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ingredientResource) Update(ctx context.Context, request resource.UpdateRequest, response *resource.UpdateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "update the ingredient")
+		return
+	}
+
+	var plan ingredientResourceModel
+	diags := request.Plan.Get(ctx, &plan)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	ingredientID, diags := plan.ingredientID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	updated, err := r.client.UpdateIngredient(ctx, ingredientID, plan.toIngredient())
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Updating HashiCups Ingredient",
+			"Could not update ingredient, unexpected error: "+err.Error(),
+		)
+		return
+	}
+
+	plan.fromIngredient(updated)
+
+	diags = response.State.Set(ctx, plan)
+	response.Diagnostics.Append(diags...)
+}
+
+func (r *ingredientResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, r.client)
+
+	if r.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "delete the ingredient")
+		return
+	}
+
+	var state ingredientResourceModel
+	diags := request.State.Get(ctx, &state)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	ingredientID, diags := state.ingredientID()
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	err := r.client.DeleteIngredient(ctx, ingredientID)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Error Deleting HashiCups Ingredient",
+			"Could not delete ingredient, unexpected error: "+err.Error(),
+		)
+		return
+	}
+}
+
+func (r *ingredientResource) Configure(_ context.Context, request resource.ConfigureRequest, _ *resource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	r.client = data.client
+	r.settings = data.settings
+}
+
+func (r *ingredientResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("id"), request, response)
+}
+
+// ingredientID parses the numeric ingredient ID of the model.
+func (m ingredientResourceModel) ingredientID() (int, diag.Diagnostics) {
+	return parseResourceID(m.ID, "Ingredient")
+}
+
+// toIngredient builds the API request body from the model.
+func (m ingredientResourceModel) toIngredient() Ingredient {
+	return Ingredient{
+		Name:     m.Name.ValueString(),
+		Quantity: int(m.Quantity.ValueInt64()),
+		Unit:     m.Unit.ValueString(),
+	}
+}
+
+// fromIngredient maps an API ingredient onto the model.
+func (m *ingredientResourceModel) fromIngredient(ingredient *Ingredient) {
+	m.ID = types.StringValue(strconv.Itoa(ingredient.ID))
+	m.Name = types.StringValue(ingredient.Name)
+	m.Quantity = types.Int64Value(int64(ingredient.Quantity))
+	m.Unit = types.StringValue(ingredient.Unit)
+}
@@ -0,0 +1,115 @@
+package hashicups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// newTestIngredientClient returns a client backed by an in-memory ingredient
+// API.
+func newTestIngredientClient(t *testing.T) *Client {
+	t.Helper()
+
+	return newTestClient(t, newTestCRUDHandler("/ingredients", "Deleted ingredient", func(ingredient *Ingredient, id int) { ingredient.ID = id }))
+}
+
+func TestIngredientResourceCRUD(t *testing.T) {
+	planned := ingredientResourceModel{
+		ID:       types.StringUnknown(),
+		Name:     types.StringValue("Espresso"),
+		Quantity: types.Int64Value(40),
+		Unit:     types.StringValue("ml"),
+	}
+
+	created, _, updated := runTestResourceCRUD(t, &ingredientResource{client: newTestIngredientClient(t)}, planned, func(m ingredientResourceModel) ingredientResourceModel {
+		m.Quantity = types.Int64Value(60)
+		return m
+	})
+
+	if created.ID.ValueString() != "1" {
+		t.Fatalf("expected ingredient ID 1, got %s", created.ID)
+	}
+	if updated.Name.ValueString() != "Espresso" || updated.Quantity.ValueInt64() != 60 || updated.Unit.ValueString() != "ml" {
+		t.Errorf("unexpected ingredient after update: %+v", updated)
+	}
+}
+
+func TestIngredientResourceImport(t *testing.T) {
+	ctx := context.Background()
+	client := newTestIngredientClient(t)
+	if _, err := client.CreateIngredient(ctx, Ingredient{Name: "Steamed Milk", Quantity: 200, Unit: "ml"}); err != nil {
+		t.Fatalf("unable to create ingredient: %s", err)
+	}
+
+	r := &ingredientResource{client: client}
+	s := testResourceSchema(t, r)
+
+	importResp := &resource.ImportStateResponse{State: testState(t, s, nil)}
+	r.ImportState(ctx, resource.ImportStateRequest{ID: "1"}, importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("import: %v", importResp.Diagnostics)
+	}
+
+	readResp := &resource.ReadResponse{State: importResp.State}
+	r.Read(ctx, resource.ReadRequest{State: importResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	var imported ingredientResourceModel
+	readResp.State.Get(ctx, &imported)
+	if imported.Name.ValueString() != "Steamed Milk" || imported.Quantity.ValueInt64() != 200 || imported.Unit.ValueString() != "ml" {
+		t.Errorf("unexpected ingredient after import: %+v", imported)
+	}
+}
+
+func TestIngredientResourceValidators(t *testing.T) {
+	s := testResourceSchema(t, &ingredientResource{})
+	quantity := s.Attributes["quantity"].(rschema.Int64Attribute)
+	unit := s.Attributes["unit"].(rschema.StringAttribute)
+
+	tests := map[string]struct {
+		quantity    int64
+		unit        string
+		expectError bool
+	}{
+		"grams":         {quantity: 18, unit: "g"},
+		"units":         {quantity: 1, unit: "unit"},
+		"zero quantity": {quantity: 0, unit: "g", expectError: true},
+		"negative":      {quantity: -5, unit: "ml", expectError: true},
+		"unknown unit":  {quantity: 2, unit: "oz", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			for _, v := range quantity.Validators {
+				resp := &validator.Int64Response{}
+				v.ValidateInt64(context.Background(), validator.Int64Request{
+					Path:        path.Root("quantity"),
+					ConfigValue: types.Int64Value(test.quantity),
+				}, resp)
+				diags.Append(resp.Diagnostics...)
+			}
+			for _, v := range unit.Validators {
+				resp := &validator.StringResponse{}
+				v.ValidateString(context.Background(), validator.StringRequest{
+					Path:        path.Root("unit"),
+					ConfigValue: types.StringValue(test.unit),
+				}, resp)
+				diags.Append(resp.Diagnostics...)
+			}
+
+			if diags.HasError() != test.expectError {
+				t.Errorf("expected error %t, got %v", test.expectError, diags)
+			}
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// GetIngredient - Returns a specific ingredient of the master list
+func (c *Client) GetIngredient(ctx context.Context, ingredientID int) (*Ingredient, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/ingredients/%d", c.baseURL(), ingredientID), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	ingredient := Ingredient{}
+	err = c.decode(body, &ingredient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ingredient, nil
+}
+
+// CreateIngredient - Create new ingredient in the master list
+func (c *Client) CreateIngredient(ctx context.Context, ingredient Ingredient) (*Ingredient, error) {
+	rb, err := c.encode(ingredient)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", fmt.Sprintf("%s/ingredients", c.baseURL()), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	newIngredient := Ingredient{}
+	err = c.decode(body, &newIngredient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &newIngredient, nil
+}
+
+// UpdateIngredient - Updates an ingredient of the master list
+func (c *Client) UpdateIngredient(ctx context.Context, ingredientID int, ingredient Ingredient) (*Ingredient, error) {
+	rb, err := c.encode(ingredient)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("%s/ingredients/%d", c.baseURL(), ingredientID), strings.NewReader(string(rb)))
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	updatedIngredient := Ingredient{}
+	err = c.decode(body, &updatedIngredient)
+	if err != nil {
+		return nil, err
+	}
+
+	return &updatedIngredient, nil
+}
+
+// DeleteIngredient - Deletes an ingredient from the master list
+func (c *Client) DeleteIngredient(ctx context.Context, ingredientID int) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/ingredients/%d", c.baseURL(), ingredientID), nil)
+	if err != nil {
+		return err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return err
+	}
+
+	if len(body) > 0 && string(body) != "Deleted ingredient" {
+		return errors.New(string(body))
+	}
+
+	return nil
+}
@@ -0,0 +1,133 @@
+package hashicups
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &ingredientsDataSource{}
+	_ datasource.DataSourceWithConfigure = &ingredientsDataSource{}
+)
+
+func NewIngredientsDataSource() datasource.DataSource {
+	return &ingredientsDataSource{}
+}
+
+type ingredientsDataSource struct {
+	client   *Client
+	settings providerSettings
+}
+
+// ingredientsDataSourceModel maps the data source schema data.
+type ingredientsDataSourceModel struct {
+	ID          types.String       `tfsdk:"id"`
+	CoffeeID    types.Int64        `tfsdk:"coffee_id"`
+	Ingredients []ingredientsModel `tfsdk:"ingredients"`
+}
+
+// ingredientsModel maps ingredient schema data.
+type ingredientsModel struct {
+	ID       types.Int64  `tfsdk:"id"`
+	Name     types.String `tfsdk:"name"`
+	Quantity types.Int64  `tfsdk:"quantity"`
+	Unit     types.String `tfsdk:"unit"`
+}
+
+func (d *ingredientsDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_ingredients"
+}
+
+// Schema defines the schema for the data source.
+func (d *ingredientsDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Fetches the ingredients of a coffee.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Identifier of the coffee, as a string.",
+			},
+			"coffee_id": schema.Int64Attribute{
+				Required:    true,
+				Description: "Numeric identifier of the coffee to read the ingredients of.",
+			},
+			"ingredients": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "List of ingredients in the coffee. Empty when the coffee has none, or null with the provider empty_lists_as_null setting.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Numeric identifier of the ingredient.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name of the ingredient.",
+						},
+						"quantity": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Amount of the ingredient in the coffee, in unit.",
+						},
+						"unit": schema.StringAttribute{
+							Computed:    true,
+							Description: "Unit of the quantity, such as `ml` or `g`.",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *ingredientsDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer addDeprecationWarnings(&resp.Diagnostics, d.client)
+
+	var state ingredientsDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	coffeeID := state.CoffeeID.ValueInt64()
+	ingredients, err := d.client.GetIngredients(ctx, int(coffeeID))
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read HashiCups Ingredients",
+			fmt.Sprintf("Could not read the ingredients of coffee ID %d: %s", coffeeID, err),
+		)
+		return
+	}
+
+	state.Ingredients = nil
+	for _, ingredient := range ingredients {
+		state.Ingredients = append(state.Ingredients, ingredientsModel{
+			ID:       types.Int64Value(int64(ingredient.ID)),
+			Name:     types.StringValue(ingredient.Name),
+			Quantity: types.Int64Value(int64(ingredient.Quantity)),
+			Unit:     types.StringValue(ingredient.Unit),
+		})
+	}
+	state.Ingredients = listOrNull(state.Ingredients, d.settings.emptyListsAsNull)
+	state.ID = types.StringValue(strconv.FormatInt(coffeeID, 10))
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *ingredientsDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	d.client = data.client
+	d.settings = data.settings
+}
@@ -0,0 +1,53 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestIngredientsDataSourceRead(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees/1/ingredients":
+			_, _ = w.Write([]byte(`[{"ingredient_id":1,"name":"Espresso","quantity":40,"unit":"ml"},{"ingredient_id":3,"name":"Steamed Milk","quantity":300,"unit":"ml"}]`))
+		case "/coffees/2/ingredients":
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+	d := &ingredientsDataSource{client: client}
+
+	read := func(coffeeID int64) ingredientsDataSourceModel {
+		t.Helper()
+		resp := readTestDataSource(t, d, &ingredientsDataSourceModel{CoffeeID: types.Int64Value(coffeeID)})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+
+		var state ingredientsDataSourceModel
+		resp.State.Get(context.Background(), &state)
+		return state
+	}
+
+	expected := []ingredientsModel{
+		{ID: types.Int64Value(1), Name: types.StringValue("Espresso"), Quantity: types.Int64Value(40), Unit: types.StringValue("ml")},
+		{ID: types.Int64Value(3), Name: types.StringValue("Steamed Milk"), Quantity: types.Int64Value(300), Unit: types.StringValue("ml")},
+	}
+	if state := read(1); !reflect.DeepEqual(state.Ingredients, expected) || state.ID.ValueString() != "1" {
+		t.Errorf("expected ingredients %v, got %+v", expected, state)
+	}
+
+	if state := read(2); state.Ingredients == nil || len(state.Ingredients) != 0 {
+		t.Errorf("expected an empty ingredient list, got %v", state.Ingredients)
+	}
+
+	resp := readTestDataSource(t, d, &ingredientsDataSourceModel{CoffeeID: types.Int64Value(9)})
+	if !resp.Diagnostics.HasError() {
+		t.Error("expected error for a missing coffee, got none")
+	}
+}
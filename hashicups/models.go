@@ -1,15 +1,31 @@
 package hashicups
 
+import "time"
+
 // Order -
 type Order struct {
-	ID    int         `json:"id,omitempty"`
-	Items []OrderItem `json:"items,omitempty"`
+	ID           int         `json:"id,omitempty"`
+	Items        []OrderItem `json:"items,omitempty"`
+	ScheduledFor *time.Time  `json:"scheduled_for,omitempty"`
+	// ExternalID is a caller-chosen key identifying the order.
+	ExternalID string `json:"external_id,omitempty"`
+	// ConfirmationCode is the code the server gives customers to refer to
+	// the order, if any.
+	ConfirmationCode string `json:"confirmation_code,omitempty"`
+}
+
+// User -
+type User struct {
+	ID       int    `json:"id"`
+	Username string `json:"username"`
 }
 
 // OrderItem -
 type OrderItem struct {
 	Coffee   Coffee `json:"coffee"`
 	Quantity int    `json:"quantity"`
+	// Note is a free-form preparation request, such as "extra hot".
+	Note string `json:"note,omitempty"`
 }
 
 // Coffee -
@@ -21,6 +37,53 @@ type Coffee struct {
 	Price       float64      `json:"price"`
 	Image       string       `json:"image"`
 	Ingredient  []Ingredient `json:"ingredients"`
+	// AvailableFrom and AvailableUntil bound seasonal coffees. A nil bound
+	// means the coffee is available without limit in that direction.
+	AvailableFrom  *time.Time `json:"available_from,omitempty"`
+	AvailableUntil *time.Time `json:"available_until,omitempty"`
+	// Stock is the number of servings available, or nil when the API does
+	// not track stock for the coffee.
+	Stock *int `json:"stock,omitempty"`
+	// MaxOrderQuantity is the largest quantity of the coffee a single order
+	// item may request, or nil when the API sets no limit.
+	MaxOrderQuantity *int `json:"max_order_quantity,omitempty"`
+	// Metadata holds the coffee labels, such as origin.
+	Metadata map[string]string `json:"metadata,omitempty"`
+	// Origin, RoastLevel, and Process describe the beans when the API
+	// reports them.
+	Origin     *string `json:"origin,omitempty"`
+	RoastLevel *string `json:"roast_level,omitempty"`
+	Process    *string `json:"process,omitempty"`
+	// LocalizedName is the name in the requested Accept-Language, when the
+	// server supports localization.
+	LocalizedName *string `json:"localized_name,omitempty"`
+	// Allergens lists the allergens the coffee contains, or is nil when the
+	// API has no allergen data for it.
+	Allergens []string `json:"allergens,omitempty"`
+	// PrepMinutes is the time to prepare one serving, or nil when the API
+	// has no preparation time for the coffee.
+	PrepMinutes *int `json:"prep_minutes,omitempty"`
+	// CreatedAt is when the coffee was added to the catalog, or nil when
+	// the API does not report it.
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// AvailableAt reports whether the coffee can be ordered at t.
+func (c Coffee) AvailableAt(t time.Time) bool {
+	if c.AvailableFrom != nil && t.Before(*c.AvailableFrom) {
+		return false
+	}
+	if c.AvailableUntil != nil && !t.Before(*c.AvailableUntil) {
+		return false
+	}
+
+	return true
+}
+
+// IsNewAt reports whether the coffee was created less than window before
+// t. Coffees without a creation time are never new.
+func (c Coffee) IsNewAt(t time.Time, window time.Duration) bool {
+	return c.CreatedAt != nil && t.Sub(*c.CreatedAt) < window
 }
 
 // Ingredient -
@@ -30,3 +93,11 @@ type Ingredient struct {
 	Quantity int    `json:"quantity"`
 	Unit     string `json:"unit"`
 }
+
+// Combo -
+type Combo struct {
+	ID        int     `json:"id,omitempty"`
+	Name      string  `json:"name"`
+	CoffeeIDs []int   `json:"coffee_ids"`
+	Price     float64 `json:"price"`
+}
@@ -0,0 +1,184 @@
+package hashicups
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &orderDataSource{}
+	_ datasource.DataSourceWithConfigure = &orderDataSource{}
+)
+
+func NewOrderDataSource() datasource.DataSource {
+	return &orderDataSource{}
+}
+
+// orderDataSource reads an order managed outside of Terraform. Its items
+// have the same shape as those of the order resource.
+type orderDataSource struct {
+	client   *Client
+	settings providerSettings
+}
+
+// orderDataSourceModel maps the data source schema data.
+type orderDataSourceModel struct {
+	ID           types.String     `tfsdk:"id"`
+	Items        []orderItemModel `tfsdk:"items"`
+	ScheduledFor types.String     `tfsdk:"scheduled_for"`
+	ExternalID   types.String     `tfsdk:"external_id"`
+	TotalPrice   types.Float64    `tfsdk:"total_price"`
+	ItemCount    types.Int64      `tfsdk:"item_count"`
+}
+
+func (d *orderDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_order"
+}
+
+// Schema defines the schema for the data source.
+func (d *orderDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Fetches an existing order.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Required:    true,
+				Description: "Numeric identifier of the order to read.",
+			},
+			"items": orderItemsDataSourceAttribute(),
+			"scheduled_for": schema.StringAttribute{
+				Computed:    true,
+				Description: "RFC3339 timestamp at which the order is placed. Null for orders placed immediately.",
+			},
+			"external_id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Caller-chosen key identifying the order. Null when the order has none.",
+			},
+			"total_price": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Total price of the order.",
+			},
+			"item_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Total quantity of coffees in the order.",
+			},
+		},
+	}
+}
+
+// orderItemsDataSourceAttribute is the schema of the computed order items read
+// by the order data sources.
+func orderItemsDataSourceAttribute() schema.ListNestedAttribute {
+	return schema.ListNestedAttribute{
+		Computed:    true,
+		Description: "List of items in the order.",
+		NestedObject: schema.NestedAttributeObject{
+			Attributes: map[string]schema.Attribute{
+				"coffee": schema.SingleNestedAttribute{
+					Computed:    true,
+					Description: "Coffee item in the order.",
+					Attributes: map[string]schema.Attribute{
+						"id": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Numeric identifier of the coffee.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Product name of the coffee.",
+						},
+						"teaser": schema.StringAttribute{
+							Computed:    true,
+							Description: "Fun tagline for the coffee.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Product description of the coffee.",
+						},
+						"price": schema.Float64Attribute{
+							Computed:    true,
+							Description: "Suggested cost of the coffee.",
+						},
+						"image": schema.StringAttribute{
+							Computed:    true,
+							Description: "URI for an image of the coffee.",
+						},
+					},
+				},
+				"quantity": schema.Int64Attribute{
+					Computed:    true,
+					Description: "Count of this item in the order.",
+				},
+				"line_total": schema.Float64Attribute{
+					Computed:    true,
+					Description: "Price of the coffee multiplied by the quantity.",
+				},
+				"note": schema.StringAttribute{
+					Computed:    true,
+					Description: "Preparation note for this item. Null when the item has none.",
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *orderDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer addDeprecationWarnings(&resp.Diagnostics, d.client)
+
+	var state orderDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	order, err := d.client.GetOrder(ctx, state.ID.ValueString())
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("id"),
+			"HashiCups Order Not Found",
+			"No order with ID "+state.ID.ValueString()+" exists. Check the ID of the order being referenced.",
+		)
+		return
+	}
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read HashiCups Order",
+			"Could not read HashiCups order ID "+state.ID.ValueString()+": "+err.Error(),
+		)
+		return
+	}
+
+	state.Items = []orderItemModel{}
+	for _, item := range sortOrderItems(order.Items, d.settings.orderItemSort) {
+		state.Items = append(state.Items, newOrderItemModel(item))
+	}
+	totalPrice, itemCount := orderItemTotals(state.Items)
+	state.TotalPrice = types.Float64Value(totalPrice)
+	state.ItemCount = types.Int64Value(itemCount)
+	state.ScheduledFor = timeValue(order.ScheduledFor)
+	state.ExternalID = types.StringNull()
+	if order.ExternalID != "" {
+		state.ExternalID = types.StringValue(order.ExternalID)
+	}
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *orderDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	d.client = data.client
+	d.settings = data.settings
+}
@@ -0,0 +1,78 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestOrderDataSourceRead(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders/7" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":7,"external_id":"ticket-42","items":[
+			{"coffee":{"id":1,"name":"HCP Aeropress","price":200},"quantity":2,"note":"extra hot"},
+			{"coffee":{"id":2,"name":"Vaulatte","price":150},"quantity":1}
+		]}`))
+	})
+	d := &orderDataSource{client: client}
+
+	resp := readTestDataSource(t, d, &orderDataSourceModel{ID: types.StringValue("7")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state orderDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if len(state.Items) != 2 {
+		t.Fatalf("expected 2 items, got %+v", state.Items)
+	}
+	if item := state.Items[0]; item.Coffee.Name.ValueString() != "HCP Aeropress" || item.Quantity.ValueInt64() != 2 ||
+		item.Note.ValueString() != "extra hot" || item.LineTotal.ValueFloat64() != 400 {
+		t.Errorf("unexpected first item: %+v", item)
+	}
+	if !state.Items[1].Note.IsNull() {
+		t.Errorf("expected null note, got %s", state.Items[1].Note)
+	}
+	if state.TotalPrice.ValueFloat64() != 550 || state.ItemCount.ValueInt64() != 3 {
+		t.Errorf("expected total_price 550 and item_count 3, got %s and %s", state.TotalPrice, state.ItemCount)
+	}
+	if state.ExternalID.ValueString() != "ticket-42" || !state.ScheduledFor.IsNull() {
+		t.Errorf("expected external_id ticket-42 and null scheduled_for, got %s and %s", state.ExternalID, state.ScheduledFor)
+	}
+
+	resp = readTestDataSource(t, d, &orderDataSourceModel{ID: types.StringValue("8")})
+	errs := resp.Diagnostics.Errors()
+	if len(errs) != 1 {
+		t.Fatalf("expected one not found error, got %v", resp.Diagnostics)
+	}
+	if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("id")) || d.Summary() != "HashiCups Order Not Found" {
+		t.Errorf("expected not found error at id, got %v", errs[0])
+	}
+}
+
+func TestOrderDataSourceReadNullItems(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":7,"items":null}`))
+	})
+
+	resp := readTestDataSource(t, &orderDataSource{client: client}, &orderDataSourceModel{ID: types.StringValue("7")})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state orderDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if state.Items == nil || len(state.Items) != 0 {
+		t.Errorf("expected empty items, got %+v", state.Items)
+	}
+	if state.TotalPrice.ValueFloat64() != 0 || state.ItemCount.ValueInt64() != 0 {
+		t.Errorf("expected zero total_price and item_count, got %s and %s", state.TotalPrice, state.ItemCount)
+	}
+}
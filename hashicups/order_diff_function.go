@@ -0,0 +1,175 @@
+package hashicups
+
+import (
+	"context"
+	"sort"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &orderDiffFunction{}
+
+var (
+	// orderDiffOrderAttrTypes is the order shape accepted by order_diff. Any
+	// extra attributes, such as those of a hashicups_order resource, are
+	// dropped by Terraform during conversion.
+	orderDiffOrderAttrTypes = map[string]attr.Type{
+		"items": types.ListType{
+			ElemType: types.ObjectType{
+				AttrTypes: map[string]attr.Type{
+					"coffee": types.ObjectType{
+						AttrTypes: map[string]attr.Type{
+							"id": types.Int64Type,
+						},
+					},
+					"quantity": types.Int64Type,
+				},
+			},
+		},
+	}
+
+	orderDiffItemAttrTypes = map[string]attr.Type{
+		"coffee_id": types.Int64Type,
+		"quantity":  types.Int64Type,
+	}
+
+	orderDiffChangeAttrTypes = map[string]attr.Type{
+		"coffee_id":    types.Int64Type,
+		"old_quantity": types.Int64Type,
+		"new_quantity": types.Int64Type,
+	}
+)
+
+func NewOrderDiffFunction() function.Function {
+	return &orderDiffFunction{}
+}
+
+type orderDiffFunction struct{}
+
+// orderDiffOrderModel maps an order-shaped function argument.
+type orderDiffOrderModel struct {
+	Items []orderDiffOrderItemModel `tfsdk:"items"`
+}
+
+// orderDiffOrderItemModel maps an item of an order-shaped function argument.
+type orderDiffOrderItemModel struct {
+	Coffee   orderDiffOrderCoffeeModel `tfsdk:"coffee"`
+	Quantity types.Int64               `tfsdk:"quantity"`
+}
+
+// orderDiffOrderCoffeeModel maps the coffee of an order item argument.
+type orderDiffOrderCoffeeModel struct {
+	ID types.Int64 `tfsdk:"id"`
+}
+
+// orderDiffModel maps the function result.
+type orderDiffModel struct {
+	Added   []orderDiffItemModel   `tfsdk:"added"`
+	Removed []orderDiffItemModel   `tfsdk:"removed"`
+	Changed []orderDiffChangeModel `tfsdk:"changed"`
+}
+
+// orderDiffItemModel maps an added or removed item in the function result.
+type orderDiffItemModel struct {
+	CoffeeID int64 `tfsdk:"coffee_id"`
+	Quantity int64 `tfsdk:"quantity"`
+}
+
+// orderDiffChangeModel maps a quantity change in the function result.
+type orderDiffChangeModel struct {
+	CoffeeID    int64 `tfsdk:"coffee_id"`
+	OldQuantity int64 `tfsdk:"old_quantity"`
+	NewQuantity int64 `tfsdk:"new_quantity"`
+}
+
+func (f *orderDiffFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "order_diff"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *orderDiffFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary: "Compare the items of two orders.",
+		Description: "Returns the items added, removed, and changed in quantity between two order-shaped objects. " +
+			"Items are matched by coffee ID and quantities of repeated coffees are summed.",
+		Parameters: []function.Parameter{
+			function.ObjectParameter{
+				Name:           "a",
+				Description:    "Original order, an object with an items list of coffee and quantity.",
+				AttributeTypes: orderDiffOrderAttrTypes,
+			},
+			function.ObjectParameter{
+				Name:           "b",
+				Description:    "Updated order, an object with an items list of coffee and quantity.",
+				AttributeTypes: orderDiffOrderAttrTypes,
+			},
+		},
+		Return: function.ObjectReturn{
+			AttributeTypes: map[string]attr.Type{
+				"added":   types.ListType{ElemType: types.ObjectType{AttrTypes: orderDiffItemAttrTypes}},
+				"removed": types.ListType{ElemType: types.ObjectType{AttrTypes: orderDiffItemAttrTypes}},
+				"changed": types.ListType{ElemType: types.ObjectType{AttrTypes: orderDiffChangeAttrTypes}},
+			},
+		},
+	}
+}
+
+// Run computes the difference between the two orders.
+func (f *orderDiffFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var a, b orderDiffOrderModel
+
+	response.Error = request.Arguments.Get(ctx, &a, &b)
+	if response.Error != nil {
+		return
+	}
+
+	response.Error = response.Result.Set(ctx, diffOrders(a, b))
+}
+
+// diffOrders compares the quantities of each coffee in a and b. Results are
+// sorted by coffee ID.
+func diffOrders(a, b orderDiffOrderModel) orderDiffModel {
+	before := orderQuantities(a)
+	after := orderQuantities(b)
+
+	diff := orderDiffModel{
+		Added:   []orderDiffItemModel{},
+		Removed: []orderDiffItemModel{},
+		Changed: []orderDiffChangeModel{},
+	}
+
+	for coffeeID, quantity := range after {
+		oldQuantity, ok := before[coffeeID]
+		switch {
+		case !ok:
+			diff.Added = append(diff.Added, orderDiffItemModel{CoffeeID: coffeeID, Quantity: quantity})
+		case oldQuantity != quantity:
+			diff.Changed = append(diff.Changed, orderDiffChangeModel{CoffeeID: coffeeID, OldQuantity: oldQuantity, NewQuantity: quantity})
+		}
+	}
+
+	for coffeeID, quantity := range before {
+		if _, ok := after[coffeeID]; !ok {
+			diff.Removed = append(diff.Removed, orderDiffItemModel{CoffeeID: coffeeID, Quantity: quantity})
+		}
+	}
+
+	sort.Slice(diff.Added, func(i, j int) bool { return diff.Added[i].CoffeeID < diff.Added[j].CoffeeID })
+	sort.Slice(diff.Removed, func(i, j int) bool { return diff.Removed[i].CoffeeID < diff.Removed[j].CoffeeID })
+	sort.Slice(diff.Changed, func(i, j int) bool { return diff.Changed[i].CoffeeID < diff.Changed[j].CoffeeID })
+
+	return diff
+}
+
+// orderQuantities returns the total quantity of each coffee in the order.
+func orderQuantities(order orderDiffOrderModel) map[int64]int64 {
+	quantities := make(map[int64]int64, len(order.Items))
+	for _, item := range order.Items {
+		quantities[item.Coffee.ID.ValueInt64()] += item.Quantity.ValueInt64()
+	}
+
+	return quantities
+}
@@ -0,0 +1,97 @@
+package hashicups
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+)
+
+// testOrderDiffOrder builds an order_diff argument from coffee ID and
+// quantity pairs.
+func testOrderDiffOrder(t *testing.T, pairs ...int64) attr.Value {
+	t.Helper()
+
+	var order orderDiffOrderModel
+	for i := 0; i < len(pairs); i += 2 {
+		order.Items = append(order.Items, orderDiffOrderItemModel{
+			Coffee:   orderDiffOrderCoffeeModel{ID: types.Int64Value(pairs[i])},
+			Quantity: types.Int64Value(pairs[i+1]),
+		})
+	}
+
+	value, diags := types.ObjectValueFrom(context.Background(), orderDiffOrderAttrTypes, order)
+	if diags.HasError() {
+		t.Fatalf("unable to build order: %v", diags)
+	}
+
+	return value
+}
+
+func TestOrderDiffFunction(t *testing.T) {
+	tests := map[string]struct {
+		a, b     []int64
+		expected orderDiffModel
+	}{
+		"unchanged": {
+			a: []int64{1, 2},
+			b: []int64{1, 2},
+			expected: orderDiffModel{
+				Added:   []orderDiffItemModel{},
+				Removed: []orderDiffItemModel{},
+				Changed: []orderDiffChangeModel{},
+			},
+		},
+		"additions": {
+			a: []int64{1, 2},
+			b: []int64{1, 2, 3, 1, 2, 4},
+			expected: orderDiffModel{
+				Added:   []orderDiffItemModel{{CoffeeID: 2, Quantity: 4}, {CoffeeID: 3, Quantity: 1}},
+				Removed: []orderDiffItemModel{},
+				Changed: []orderDiffChangeModel{},
+			},
+		},
+		"removals": {
+			a: []int64{1, 2, 2, 1},
+			b: []int64{2, 1},
+			expected: orderDiffModel{
+				Added:   []orderDiffItemModel{},
+				Removed: []orderDiffItemModel{{CoffeeID: 1, Quantity: 2}},
+				Changed: []orderDiffChangeModel{},
+			},
+		},
+		"quantity changes": {
+			a: []int64{1, 2, 2, 1},
+			b: []int64{1, 5, 2, 1, 2, 1},
+			expected: orderDiffModel{
+				Added:   []orderDiffItemModel{},
+				Removed: []orderDiffItemModel{},
+				Changed: []orderDiffChangeModel{
+					{CoffeeID: 1, OldQuantity: 2, NewQuantity: 5},
+					{CoffeeID: 2, OldQuantity: 1, NewQuantity: 2},
+				},
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := runTestFunction(t, NewOrderDiffFunction(), testOrderDiffOrder(t, test.a...), testOrderDiffOrder(t, test.b...))
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+
+			var got orderDiffModel
+			diags := resp.Result.Value().(types.Object).As(context.Background(), &got, basetypes.ObjectAsOptions{})
+			if diags.HasError() {
+				t.Fatalf("unable to read result: %v", diags)
+			}
+			if !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("expected %+v, got %+v", test.expected, got)
+			}
+		})
+	}
+}
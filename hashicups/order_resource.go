@@ -2,40 +2,74 @@ package hashicups
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
 	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
 )
 
 var (
-	_ resource.Resource                = &orderResource{}
-	_ resource.ResourceWithConfigure   = &orderResource{}
-	_ resource.ResourceWithImportState = &orderResource{}
+	_ resource.Resource                   = &orderResource{}
+	_ resource.ResourceWithConfigure      = &orderResource{}
+	_ resource.ResourceWithImportState    = &orderResource{}
+	_ resource.ResourceWithModifyPlan     = &orderResource{}
+	_ resource.ResourceWithValidateConfig = &orderResource{}
 )
 
 type orderResource struct {
-	client *Client
+	client   *Client
+	settings providerSettings
+	catalog  *coffeeCatalog
 }
 
 // orderResourceModel maps the resource schema data.
 type orderResourceModel struct {
-	ID          types.String     `tfsdk:"id"`
-	Items       []orderItemModel `tfsdk:"items"`
-	LastUpdated types.String     `tfsdk:"last_updated"`
+	ID               types.String     `tfsdk:"id"`
+	Items            []orderItemModel `tfsdk:"items"`
+	FromCoffees      types.List       `tfsdk:"from_coffees"`
+	Quantity         types.Int64      `tfsdk:"quantity"`
+	ScheduledFor     types.String     `tfsdk:"scheduled_for"`
+	ExternalID       types.String     `tfsdk:"external_id"`
+	TotalPrice       types.Float64    `tfsdk:"total_price"`
+	Total            types.Float64    `tfsdk:"total"`
+	ItemCount        types.Int64      `tfsdk:"item_count"`
+	PrepMinutes      types.Int64      `tfsdk:"estimated_prep_minutes"`
+	OrderedBy        types.String     `tfsdk:"ordered_by"`
+	LastUpdated      types.String     `tfsdk:"last_updated"`
+	ManagedHost      types.String     `tfsdk:"managed_host"`
+	ConfirmationCode types.String     `tfsdk:"confirmation_code"`
+	Timeouts         timeouts.Value   `tfsdk:"timeouts"`
 }
 
 // orderItemModel maps order item data.
 type orderItemModel struct {
-	Coffee   orderItemCoffeeModel `tfsdk:"coffee"`
-	Quantity types.Int64          `tfsdk:"quantity"`
+	Coffee    orderItemCoffeeModel `tfsdk:"coffee"`
+	Quantity  types.Int64          `tfsdk:"quantity"`
+	Note      types.String         `tfsdk:"note"`
+	LineTotal types.Float64        `tfsdk:"line_total"`
 }
 
+// maxOrderItemNoteLength is the longest note accepted for an order item.
+const maxOrderItemNoteLength = 140
+
 // orderItemCoffeeModel maps coffee order item data.
 type orderItemCoffeeModel struct {
 	ID          types.Int64   `tfsdk:"id"`
@@ -58,6 +92,14 @@ func (o *orderResource) Metadata(ctx context.Context, request resource.MetadataR
 func (o *orderResource) Schema(ctx context.Context, request resource.SchemaRequest, response *resource.SchemaResponse) {
 	response.Schema = schema.Schema{
 		Description: "Manages an order.",
+		Blocks: map[string]schema.Block{
+			"timeouts": timeouts.Block(ctx, timeouts.Opts{
+				Create: true,
+				Read:   true,
+				Update: true,
+				Delete: true,
+			}),
+		},
 		Attributes: map[string]schema.Attribute{
 			"id": schema.StringAttribute{
 				Computed:    true,
@@ -68,11 +110,81 @@ func (o *orderResource) Schema(ctx context.Context, request resource.SchemaReque
 			},
 			"last_updated": schema.StringAttribute{
 				Computed:    true,
-				Description: "Timestamp of the last Terraform update of the order.",
+				Description: "Timestamp of the last Terraform update that changed the items of the order.",
+			},
+			"ordered_by": schema.StringAttribute{
+				Computed:    true,
+				Description: "Username of the HashiCups user that created the order. Null when the user could not be read or the order was imported.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"confirmation_code": schema.StringAttribute{
+				Computed: true,
+				Description: "Human-friendly code referring to the order. Taken from the HashiCups API when it returns one, " +
+					"and otherwise derived from the order ID and items when the order is created. Kept when the order is updated.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"managed_host": schema.StringAttribute{
+				Computed: true,
+				Description: "HashiCups host the order was created on. Order IDs are specific to a host, " +
+					"so changing the provider host replaces the order rather than updating it.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"from_coffees": schema.ListAttribute{
+				ElementType: types.Int64Type,
+				Optional:    true,
+				Description: "List of coffee IDs to order, such as `data.hashicups_coffees.example.coffees[*].id`. " +
+					"Each coffee becomes an item with the uniform quantity. Conflicts with items.",
+			},
+			"quantity": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Count of each coffee ordered through from_coffees. Defaults to 1.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"scheduled_for": schema.StringAttribute{
+				Optional:    true,
+				Description: "RFC3339 timestamp at which the order is placed. Must be in the future when the order is created. Changing it replaces the order.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"total_price": schema.Float64Attribute{
+				Computed:    true,
+				Description: "Total price of the order. Known at plan time when every planned coffee is in the catalog.",
+			},
+			"total": schema.Float64Attribute{
+				Computed: true,
+				Description: "Sum of each item's coffee price times its quantity, the same value as total_price. " +
+					"It is recomputed on every read, so coffee price changes show up as drift.",
+			},
+			"item_count": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Total quantity of coffees in the order.",
+			},
+			"estimated_prep_minutes": schema.Int64Attribute{
+				Computed: true,
+				Description: "Estimated minutes to prepare the order from the catalog prep time of each coffee multiplied by its quantity, " +
+					"combined as set by the provider prep_time_mode. Null when a coffee has no prep time in the catalog.",
+			},
+			"external_id": schema.StringAttribute{
+				Optional: true,
+				Description: "Caller-chosen key identifying the order. On create, an existing order with the same key is " +
+					"adopted and updated instead of creating a duplicate. Changing it replaces the order.",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
 			},
 			"items": schema.ListNestedAttribute{
-				Required:    true,
-				Description: "List of items in the order.",
+				Optional:    true,
+				Computed:    true,
+				Description: "List of items in the order. Computed when from_coffees is set.",
 				NestedObject: schema.NestedAttributeObject{
 					Attributes: map[string]schema.Attribute{
 						"coffee": schema.SingleNestedAttribute{
@@ -80,11 +192,13 @@ func (o *orderResource) Schema(ctx context.Context, request resource.SchemaReque
 							Description: "Coffee item in the order.",
 							Attributes: map[string]schema.Attribute{
 								"id": schema.Int64Attribute{
-									Description: "Numeric identifier of the coffee.",
-									Required:    true,
+									Description: "Numeric identifier of the coffee. Either id or name must be set.",
+									Optional:    true,
+									Computed:    true,
 								},
 								"name": schema.StringAttribute{
-									Description: "Product name of the coffee.",
+									Description: "Product name of the coffee. When id is not set, the coffee is looked up by its exact name in the catalog. When id is set, the name must match the catalog name of that coffee.",
+									Optional:    true,
 									Computed:    true,
 								},
 								"teaser": schema.StringAttribute{
@@ -107,7 +221,21 @@ func (o *orderResource) Schema(ctx context.Context, request resource.SchemaReque
 						},
 						"quantity": schema.Int64Attribute{
 							Required:    true,
-							Description: "Count of this item in the order.",
+							Description: "Count of this item in the order. Must be at least 1.",
+							Validators: []validator.Int64{
+								int64validator.AtLeast(1),
+							},
+						},
+						"line_total": schema.Float64Attribute{
+							Computed:    true,
+							Description: "Price of the coffee multiplied by the quantity.",
+						},
+						"note": schema.StringAttribute{
+							Optional:    true,
+							Description: fmt.Sprintf("Preparation note for this item, such as `extra hot`. At most %d characters. Servers without note support ignore it, and the configured note is kept in state.", maxOrderItemNoteLength),
+							Validators: []validator.String{
+								stringvalidator.LengthBetween(1, maxOrderItemNoteLength),
+							},
 						},
 					},
 				},
@@ -116,7 +244,588 @@ func (o *orderResource) Schema(ctx context.Context, request resource.SchemaReque
 	}
 }
 
+// addReadOnlyItemErrors reports computed-only item attributes set in the
+// configuration, which the provider would otherwise overwrite from the API.
+func addReadOnlyItemErrors(diags *diag.Diagnostics, index int, item orderItemModel) {
+	itemPath := path.Root("items").AtListIndex(index)
+	coffeePath := itemPath.AtName("coffee")
+	readOnly := []struct {
+		path  path.Path
+		value attr.Value
+	}{
+		{coffeePath.AtName("teaser"), item.Coffee.Teaser},
+		{coffeePath.AtName("description"), item.Coffee.Description},
+		{coffeePath.AtName("price"), item.Coffee.Price},
+		{coffeePath.AtName("image"), item.Coffee.Image},
+		{itemPath.AtName("line_total"), item.LineTotal},
+	}
+
+	for _, attribute := range readOnly {
+		if attribute.value.IsNull() || attribute.value.IsUnknown() {
+			continue
+		}
+
+		diags.AddAttributeError(
+			attribute.path,
+			"Read-Only HashiCups Order Attribute",
+			fmt.Sprintf("The %s attribute is computed from the HashiCups catalog and cannot be set. Remove it from the configuration; "+
+				"coffees are chosen by id or name only.", attribute.path),
+		)
+	}
+}
+
+// coffeeReference is a coffee ID in the configuration and where it appears.
+type coffeeReference struct {
+	path string
+	id   int64
+}
+
+// ValidateConfig rejects coffees listed in more than one item and, when the
+// provider validate_coffee_ids setting is enabled, checks the configured
+// coffee IDs against the catalog. Every unknown ID is reported in a single
+// error.
+func (o *orderResource) ValidateConfig(ctx context.Context, request resource.ValidateConfigRequest, response *resource.ValidateConfigResponse) {
+	var items types.List
+	diags := request.Config.GetAttribute(ctx, path.Root("items"), &items)
+	response.Diagnostics.Append(diags...)
+
+	var fromCoffees types.List
+	diags = request.Config.GetAttribute(ctx, path.Root("from_coffees"), &fromCoffees)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	var references []coffeeReference
+
+	if !items.IsNull() && !items.IsUnknown() {
+		var itemModels []orderItemModel
+		// Items that are not yet known are checked during planning.
+		if diags := items.ElementsAs(ctx, &itemModels, false); !diags.HasError() {
+			for i, item := range itemModels {
+				if !item.Coffee.ID.IsNull() && !item.Coffee.ID.IsUnknown() {
+					references = append(references, coffeeReference{fmt.Sprintf("items[%d]", i), item.Coffee.ID.ValueInt64()})
+				}
+				addReadOnlyItemErrors(&response.Diagnostics, i, item)
+			}
+		}
+	}
+	addDuplicateCoffeeErrors(&response.Diagnostics, references)
+
+	if !fromCoffees.IsNull() && !fromCoffees.IsUnknown() {
+		var ids []types.Int64
+		diags = fromCoffees.ElementsAs(ctx, &ids, false)
+		response.Diagnostics.Append(diags...)
+		for i, id := range ids {
+			if !id.IsUnknown() {
+				references = append(references, coffeeReference{fmt.Sprintf("from_coffees[%d]", i), id.ValueInt64()})
+			}
+		}
+	}
+
+	// The provider is not configured during early validation.
+	if !o.settings.validateCoffeeIDs || o.catalog == nil || len(references) == 0 {
+		return
+	}
+
+	catalog, err := o.catalog.coffeeIDs(ctx)
+	if err != nil {
+		response.Diagnostics.AddWarning(
+			"Unable to Validate HashiCups Coffee IDs",
+			"Could not read the coffee catalog, skipping the coffee ID check: "+err.Error(),
+		)
+		return
+	}
+
+	var unknown []string
+	for _, reference := range references {
+		if !catalog[reference.id] {
+			unknown = append(unknown, fmt.Sprintf("%d (%s)", reference.id, reference.path))
+		}
+	}
+
+	if len(unknown) > 0 {
+		response.Diagnostics.AddError(
+			"Unknown HashiCups Coffees",
+			"The following coffee IDs do not exist in the HashiCups catalog: "+strings.Join(unknown, ", ")+".",
+		)
+	}
+}
+
+// addDuplicateCoffeeErrors adds an error for each coffee ID referenced more
+// than once, listing where it appears. The API would otherwise create a
+// separate line item for each reference.
+func addDuplicateCoffeeErrors(diags *diag.Diagnostics, references []coffeeReference) {
+	var ids []int64
+	paths := make(map[int64][]string, len(references))
+	for _, reference := range references {
+		if _, ok := paths[reference.id]; !ok {
+			ids = append(ids, reference.id)
+		}
+		paths[reference.id] = append(paths[reference.id], reference.path)
+	}
+
+	for _, id := range ids {
+		if len(paths[id]) < 2 {
+			continue
+		}
+
+		diags.AddAttributeError(
+			path.Root("items"),
+			"Duplicate HashiCups Order Coffee",
+			fmt.Sprintf("Coffee ID %d is listed in %s. List each coffee once and set its quantity instead.", id, strings.Join(paths[id], ", ")),
+		)
+	}
+}
+
+// ModifyPlan expands from_coffees into individual order items and checks the
+// planned items against the catalog.
+func (o *orderResource) ModifyPlan(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, o.client)
+
+	// Nothing to plan when the resource is being destroyed.
+	if request.Plan.Raw.IsNull() {
+		return
+	}
+
+	o.planManagedHost(ctx, request, response)
+	o.expandFromCoffees(ctx, request, response)
+	o.resolveCoffeeNames(ctx, request, response)
+	o.validateScheduledFor(ctx, request, response)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	// The remaining checks need the planned items and share one read of the
+	// catalog.
+	items, ok := plannedOrderItems(ctx, response)
+	if !ok || o.client == nil {
+		return
+	}
+	coffees, err := o.catalogCoffees(ctx)
+	if err != nil {
+		response.Diagnostics.AddWarning(
+			"Unable to Read HashiCups Coffee Catalog",
+			"Could not read the coffee catalog, skipping the order quantity checks and leaving the order totals unknown: "+err.Error(),
+		)
+		return
+	}
+
+	o.checkMaxOrderQuantity(items, coffees, response)
+	if o.settings.checkStock {
+		o.checkStock(items, coffees, response)
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	o.planTotals(ctx, items, coffees, response)
+}
+
+// catalogCoffees returns the coffee catalog keyed by ID, reading it only on
+// first use.
+func (o *orderResource) catalogCoffees(ctx context.Context) (map[int64]Coffee, error) {
+	if o.catalog == nil {
+		o.catalog = &coffeeCatalog{client: o.client}
+	}
+
+	return o.catalog.coffeesByID(ctx)
+}
+
+// planManagedHost replaces the order when the provider host differs from the
+// host recorded in state, since the order ID may not exist on the new host.
+func (o *orderResource) planManagedHost(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	if request.State.Raw.IsNull() || o.client == nil {
+		return
+	}
+
+	var managedHost types.String
+	diags := request.State.GetAttribute(ctx, path.Root("managed_host"), &managedHost)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() || managedHost.IsNull() || sameHost(managedHost.ValueString(), o.client.HostURL) {
+		return
+	}
+
+	diags = response.Plan.SetAttribute(ctx, path.Root("managed_host"), o.client.HostURL)
+	response.Diagnostics.Append(diags...)
+	response.RequiresReplace = append(response.RequiresReplace, path.Root("managed_host"))
+}
+
+// sameHost reports whether two host URLs are equal, ignoring a trailing
+// slash.
+func sameHost(a, b string) bool {
+	return strings.TrimSuffix(a, "/") == strings.TrimSuffix(b, "/")
+}
+
+// planTotals computes the planned line totals, total price, and item count
+// from the catalog. They stay unknown while any coffee or quantity is unknown
+// or a coffee is missing from the catalog.
+func (o *orderResource) planTotals(ctx context.Context, items []orderItemModel, coffees map[int64]Coffee, response *resource.ModifyPlanResponse) {
+	for _, item := range items {
+		if item.Coffee.ID.IsUnknown() || item.Quantity.IsUnknown() {
+			return
+		}
+	}
+
+	var totalPrice float64
+	var itemCount int64
+	for i, item := range items {
+		coffee, ok := coffees[item.Coffee.ID.ValueInt64()]
+		if !ok {
+			return
+		}
+
+		lineTotal := coffee.Price * float64(item.Quantity.ValueInt64())
+		items[i].LineTotal = types.Float64Value(lineTotal)
+		totalPrice += lineTotal
+		itemCount += item.Quantity.ValueInt64()
+	}
+
+	diags := response.Plan.SetAttribute(ctx, path.Root("items"), items)
+	response.Diagnostics.Append(diags...)
+	diags = response.Plan.SetAttribute(ctx, path.Root("total_price"), totalPrice)
+	response.Diagnostics.Append(diags...)
+	diags = response.Plan.SetAttribute(ctx, path.Root("total"), totalPrice)
+	response.Diagnostics.Append(diags...)
+	diags = response.Plan.SetAttribute(ctx, path.Root("item_count"), itemCount)
+	response.Diagnostics.Append(diags...)
+}
+
+// expandFromCoffees plans one item per coffee in from_coffees.
+func (o *orderResource) expandFromCoffees(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var configItems types.List
+	diags := request.Config.GetAttribute(ctx, path.Root("items"), &configItems)
+	response.Diagnostics.Append(diags...)
+
+	var fromCoffees types.List
+	diags = request.Config.GetAttribute(ctx, path.Root("from_coffees"), &fromCoffees)
+	response.Diagnostics.Append(diags...)
+
+	var quantity types.Int64
+	diags = request.Config.GetAttribute(ctx, path.Root("quantity"), &quantity)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if fromCoffees.IsNull() {
+		if configItems.IsNull() {
+			response.Diagnostics.AddAttributeError(
+				path.Root("items"),
+				"Missing Order Items",
+				"Either items or from_coffees must be configured.",
+			)
+		}
+		return
+	}
+
+	if !configItems.IsNull() {
+		response.Diagnostics.AddAttributeError(
+			path.Root("from_coffees"),
+			"Conflicting Order Items",
+			"Only one of items or from_coffees may be configured.",
+		)
+		return
+	}
+
+	if fromCoffees.IsUnknown() || quantity.IsUnknown() {
+		return
+	}
+
+	var coffeeIDs []types.Int64
+	diags = fromCoffees.ElementsAs(ctx, &coffeeIDs, false)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if len(coffeeIDs) == 0 {
+		response.Diagnostics.AddAttributeError(
+			path.Root("from_coffees"),
+			"Empty Coffee Selection",
+			"The from_coffees list must contain at least one coffee ID. Check that the referenced coffees data source filter matches any coffees.",
+		)
+		return
+	}
+
+	itemQuantity := int64(1)
+	if !quantity.IsNull() {
+		itemQuantity = quantity.ValueInt64()
+	}
+
+	var items []orderItemModel
+	for _, coffeeID := range coffeeIDs {
+		if coffeeID.IsUnknown() {
+			return
+		}
+
+		items = append(items, orderItemModel{
+			Coffee: orderItemCoffeeModel{
+				ID:          coffeeID,
+				Name:        types.StringUnknown(),
+				Teaser:      types.StringUnknown(),
+				Description: types.StringUnknown(),
+				Price:       types.Float64Unknown(),
+				Image:       types.StringUnknown(),
+			},
+			Quantity:  types.Int64Value(itemQuantity),
+			Note:      types.StringNull(),
+			LineTotal: types.Float64Unknown(),
+		})
+	}
+
+	// Keep the computed coffee attributes from state when the expansion has
+	// not changed, so the plan shows no difference.
+	if !request.State.Raw.IsNull() {
+		var stateItems []orderItemModel
+		diags = request.State.GetAttribute(ctx, path.Root("items"), &stateItems)
+		response.Diagnostics.Append(diags...)
+		if sameOrderItems(stateItems, items) {
+			items = stateItems
+		}
+	}
+
+	diags = response.Plan.SetAttribute(ctx, path.Root("items"), items)
+	response.Diagnostics.Append(diags...)
+}
+
+// resolveCoffeeNames plans the coffee ID of each configured item that names
+// its coffee instead, erroring unless the name matches exactly one coffee in
+// the catalog. An item setting both keeps its id, but errors when the name is
+// not the catalog name of that coffee, as the API would report it back.
+func (o *orderResource) resolveCoffeeNames(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var configList types.List
+	diags := request.Config.GetAttribute(ctx, path.Root("items"), &configList)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() || configList.IsNull() || configList.IsUnknown() {
+		return
+	}
+
+	var configItems []orderItemModel
+	diags = configList.ElementsAs(ctx, &configItems, false)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	byName := map[int]string{}
+	withID := map[int]orderItemCoffeeModel{}
+	for i, item := range configItems {
+		switch {
+		case !item.Coffee.ID.IsNull():
+			if !item.Coffee.ID.IsUnknown() && !item.Coffee.Name.IsNull() && !item.Coffee.Name.IsUnknown() {
+				withID[i] = item.Coffee
+			}
+		case item.Coffee.Name.IsNull():
+			response.Diagnostics.AddAttributeError(
+				path.Root("items").AtListIndex(i).AtName("coffee"),
+				"Missing HashiCups Coffee",
+				fmt.Sprintf("Item %d must set either the coffee id or name.", i),
+			)
+		case !item.Coffee.Name.IsUnknown():
+			byName[i] = item.Coffee.Name.ValueString()
+		}
+	}
+	if len(byName)+len(withID) == 0 || response.Diagnostics.HasError() || o.client == nil {
+		return
+	}
+
+	items, ok := plannedOrderItems(ctx, response)
+	if !ok {
+		return
+	}
+
+	coffees, err := o.catalogCoffees(ctx)
+	if err != nil {
+		response.Diagnostics.AddError(
+			"Unable to Resolve HashiCups Coffee Names",
+			"Could not read the coffee catalog to look up coffees by name: "+err.Error(),
+		)
+		return
+	}
+
+	for i, configured := range withID {
+		for _, coffee := range coffees {
+			if int64(coffee.ID) == configured.ID.ValueInt64() && coffee.Name != configured.Name.ValueString() {
+				response.Diagnostics.AddAttributeError(
+					path.Root("items").AtListIndex(i).AtName("coffee").AtName("name"),
+					"Mismatched HashiCups Coffee Name",
+					fmt.Sprintf("Item %d names coffee %q, but coffee ID %d is %q in the HashiCups catalog. Remove the name or set it to match.", i, configured.Name.ValueString(), coffee.ID, coffee.Name),
+				)
+			}
+		}
+	}
+
+	for i, name := range byName {
+		var matches []Coffee
+		for _, coffee := range coffees {
+			if coffee.Name == name {
+				matches = append(matches, coffee)
+			}
+		}
+
+		namePath := path.Root("items").AtListIndex(i).AtName("coffee").AtName("name")
+		switch len(matches) {
+		case 0:
+			response.Diagnostics.AddAttributeError(
+				namePath,
+				"Unknown HashiCups Coffee Name",
+				fmt.Sprintf("Item %d names coffee %q, which does not exist in the HashiCups catalog.", i, name),
+			)
+		case 1:
+			items[i].Coffee.ID = types.Int64Value(int64(matches[0].ID))
+		default:
+			sort.Slice(matches, func(a, b int) bool { return matches[a].ID < matches[b].ID })
+			var ids []string
+			for _, coffee := range matches {
+				ids = append(ids, strconv.Itoa(coffee.ID))
+			}
+			response.Diagnostics.AddAttributeError(
+				namePath,
+				"Ambiguous HashiCups Coffee Name",
+				fmt.Sprintf("Item %d names coffee %q, which matches coffee IDs %s. Set the coffee id instead.", i, name, strings.Join(ids, ", ")),
+			)
+		}
+	}
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	diags = response.Plan.SetAttribute(ctx, path.Root("items"), items)
+	response.Diagnostics.Append(diags...)
+}
+
+// validateScheduledFor errors when a new or changed scheduled_for is not a
+// future RFC3339 timestamp.
+func (o *orderResource) validateScheduledFor(ctx context.Context, request resource.ModifyPlanRequest, response *resource.ModifyPlanResponse) {
+	var planned, prior types.String
+	diags := request.Plan.GetAttribute(ctx, path.Root("scheduled_for"), &planned)
+	response.Diagnostics.Append(diags...)
+	if !request.State.Raw.IsNull() {
+		diags = request.State.GetAttribute(ctx, path.Root("scheduled_for"), &prior)
+		response.Diagnostics.Append(diags...)
+	}
+	if response.Diagnostics.HasError() || planned.IsNull() || planned.IsUnknown() || planned.Equal(prior) {
+		return
+	}
+
+	scheduledFor, err := time.Parse(time.RFC3339, planned.ValueString())
+	if err != nil {
+		response.Diagnostics.AddAttributeError(
+			path.Root("scheduled_for"),
+			"Invalid Order Schedule",
+			"The scheduled_for value must be an RFC3339 timestamp, such as 2030-01-02T15:04:05Z: "+err.Error(),
+		)
+		return
+	}
+
+	if now := o.settings.currentTime(); !scheduledFor.After(now) {
+		response.Diagnostics.AddAttributeError(
+			path.Root("scheduled_for"),
+			"Invalid Order Schedule",
+			fmt.Sprintf("The scheduled_for value %s is not in the future. The current time is %s.",
+				planned.ValueString(), now.Format(time.RFC3339)),
+		)
+	}
+}
+
+// checkStock errors when a planned item orders more of a coffee than is in
+// stock. Coffees without stock data are skipped.
+func (o *orderResource) checkStock(items []orderItemModel, coffees map[int64]Coffee, response *resource.ModifyPlanResponse) {
+	for i, item := range items {
+		if item.Coffee.ID.IsUnknown() || item.Quantity.IsUnknown() {
+			continue
+		}
+
+		coffee, ok := coffees[item.Coffee.ID.ValueInt64()]
+		if !ok || coffee.Stock == nil {
+			continue
+		}
+
+		if quantity := item.Quantity.ValueInt64(); quantity > int64(*coffee.Stock) {
+			response.Diagnostics.AddAttributeError(
+				path.Root("items").AtListIndex(i).AtName("quantity"),
+				"Insufficient HashiCups Coffee Stock",
+				fmt.Sprintf("Item %d orders %d of %q (coffee ID %d) but only %d are in stock, a shortfall of %d.",
+					i, quantity, coffee.Name, coffee.ID, *coffee.Stock, quantity-int64(*coffee.Stock)),
+			)
+		}
+	}
+}
+
+// checkMaxOrderQuantity errors when a planned item orders more of a coffee
+// than its catalog max_order_quantity allows. Coffees without the limit are
+// not checked.
+func (o *orderResource) checkMaxOrderQuantity(items []orderItemModel, coffees map[int64]Coffee, response *resource.ModifyPlanResponse) {
+	for i, item := range items {
+		if item.Coffee.ID.IsUnknown() || item.Quantity.IsUnknown() {
+			continue
+		}
+
+		coffee, ok := coffees[item.Coffee.ID.ValueInt64()]
+		if !ok || coffee.MaxOrderQuantity == nil {
+			continue
+		}
+
+		if quantity := item.Quantity.ValueInt64(); quantity > int64(*coffee.MaxOrderQuantity) {
+			response.Diagnostics.AddAttributeError(
+				path.Root("items").AtListIndex(i).AtName("quantity"),
+				"HashiCups Order Quantity Exceeds Maximum",
+				fmt.Sprintf("Item %d orders %d of %q (coffee ID %d) but at most %d may be ordered at once.",
+					i, quantity, coffee.Name, coffee.ID, *coffee.MaxOrderQuantity),
+			)
+		}
+	}
+}
+
+// plannedOrderItems returns the items in the plan, or false when they are not
+// yet known.
+func plannedOrderItems(ctx context.Context, response *resource.ModifyPlanResponse) ([]orderItemModel, bool) {
+	var itemList types.List
+	diags := response.Plan.GetAttribute(ctx, path.Root("items"), &itemList)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() || itemList.IsUnknown() || itemList.IsNull() {
+		return nil, false
+	}
+
+	var items []orderItemModel
+	diags = itemList.ElementsAs(ctx, &items, false)
+	response.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return nil, false
+	}
+
+	return items, true
+}
+
+// sameOrderItems reports whether a and b order the same coffees in the same
+// quantities and positions.
+func sameOrderItems(a, b []orderItemModel) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if !a[i].Coffee.ID.Equal(b[i].Coffee.ID) || !a[i].Quantity.Equal(b[i].Quantity) {
+			return false
+		}
+	}
+
+	return true
+}
+
 func (o *orderResource) Create(ctx context.Context, request resource.CreateRequest, response *resource.CreateResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, o.client)
+
+	if o.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "create the order")
+		return
+	}
+
+	if addOrderingHoursError(&response.Diagnostics, o.settings, "create the order") {
+		return
+	}
+
 	var plan orderResourceModel
 	diags := request.Plan.Get(ctx, &plan)
 	response.Diagnostics.Append(diags...)
@@ -124,6 +833,14 @@ func (o *orderResource) Create(ctx context.Context, request resource.CreateReque
 		return
 	}
 
+	createTimeout, diags := plan.Timeouts.Create(ctx, o.defaultTimeout())
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOrderTimeout(ctx, createTimeout)
+	defer cancel()
+
 	var items []OrderItem
 	for _, item := range plan.Items {
 		items = append(items, OrderItem{
@@ -131,33 +848,115 @@ func (o *orderResource) Create(ctx context.Context, request resource.CreateReque
 				ID: int(item.Coffee.ID.ValueInt64()),
 			},
 			Quantity: int(item.Quantity.ValueInt64()),
+			Note:     item.Note.ValueString(),
 		})
 	}
 
-	order, err := o.client.CreateOrder(items)
+	options := OrderOptions{ExternalID: plan.ExternalID.ValueString()}
+	if !plan.ScheduledFor.IsNull() {
+		// The timestamp was validated during planning.
+		scheduledFor, _ := time.Parse(time.RFC3339, plan.ScheduledFor.ValueString())
+		options.ScheduledFor = &scheduledFor
+	}
+
+	var existing *Order
+	var err error
+	if options.ExternalID != "" {
+		existing, err = o.client.FindOrderByExternalID(ctx, options.ExternalID)
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Error Finding HashiCups Order",
+				"Could not look up an existing order with external ID "+options.ExternalID+": "+err.Error(),
+			)
+			return
+		}
+	}
+
+	firstBatch := items
+	if size := o.settings.createBatchSize; size > 0 && len(items) > size {
+		firstBatch = items[:size]
+	}
+
+	var order *Order
+	var orderID string
+	if existing != nil {
+		tflog.Info(ctx, "Adopting existing HashiCups order", map[string]any{"id": existing.ID, "external_id": options.ExternalID})
+		orderID = strconv.Itoa(existing.ID)
+		_, err = o.client.UpdateOrder(ctx, orderID, items)
+	} else {
+		order, err = o.client.CreateOrderWithOptions(ctx, firstBatch, options)
+		if err == nil {
+			orderID = strconv.Itoa(order.ID)
+		}
+	}
 	if err != nil {
-		response.Diagnostics.AddError(
+		addOrderAPIError(&response.Diagnostics,
 			"Error creating order",
 			"An unexpected error was encountered trying to create the order."+err.Error(),
+			err,
 		)
 		return
 	}
 
-	plan.ID = types.StringValue(strconv.Itoa(order.ID))
-	for orderItemIndex, orderItem := range order.Items {
-		plan.Items[orderItemIndex] = orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.Int64Value(int64(orderItem.Coffee.ID)),
-				Name:        types.StringValue(orderItem.Coffee.Name),
-				Teaser:      types.StringValue(orderItem.Coffee.Teaser),
-				Description: types.StringValue(orderItem.Coffee.Description),
-				Price:       types.Float64Value(orderItem.Coffee.Price),
-				Image:       types.StringValue(orderItem.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(orderItem.Quantity)),
+	// Save the ID before anything else can fail, so the order is never left
+	// out of state. Read fills in the other attributes on the next refresh.
+	diags = response.State.SetAttribute(ctx, path.Root("id"), orderID)
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+
+	if order != nil && len(firstBatch) < len(items) {
+		added, err := o.addOrderItemBatches(ctx, orderID, items, len(firstBatch))
+		if err != nil {
+			response.Diagnostics.AddError(
+				"Error Creating HashiCups Order",
+				fmt.Sprintf("Order ID %s was created, but only %d of its %d items were added before a batch failed: %s. "+
+					"Its ID was saved to state, so the next apply replaces the partially created order.", orderID, added, len(items), err),
+			)
+			return
+		}
+		order = nil
+	}
+
+	// Update responses do not include the items, so adopted orders and
+	// orders created in batches are read back.
+	if order == nil {
+		order, err = o.client.GetOrder(ctx, orderID)
+		if err != nil {
+			outcome := "was created with the planned items"
+			if existing != nil {
+				outcome = "was adopted and updated to the planned items"
+			}
+			response.Diagnostics.AddError(
+				"Error Reading HashiCups Order",
+				"Order ID "+orderID+" "+outcome+" but could not be read back. "+
+					"Its ID was saved to state and the next refresh will read it: "+err.Error(),
+			)
+			return
 		}
 	}
-	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
+
+	if changes := orderItemChanges(items, order.Items); len(changes) > 0 {
+		response.Diagnostics.AddWarning(
+			"HashiCups Order Altered By Server",
+			"The HashiCups API confirmed the order with different items than requested:\n\n"+strings.Join(changes, "\n"),
+		)
+	}
+
+	plan.ID = types.StringValue(strconv.Itoa(order.ID))
+	plan.ConfirmationCode = types.StringValue(orderConfirmationCode(order))
+	plan.OrderedBy = o.orderedBy(ctx, &response.Diagnostics)
+	plan.ManagedHost = types.StringValue(o.client.HostURL)
+	planned := plan.Items
+	plan.Items = make([]orderItemModel, 0, len(order.Items))
+	for _, orderItem := range order.Items {
+		plan.Items = append(plan.Items, newOrderItemModel(orderItem))
+	}
+	keepItemNotes(plan.Items, planned)
+	plan.setTotals()
+	plan.PrepMinutes = o.estimatedPrepMinutes(ctx, plan.Items)
+	plan.LastUpdated = types.StringValue(o.settings.formatTimestamp(o.settings.currentTime()))
 
 	diags = response.State.Set(ctx, plan)
 	response.Diagnostics.Append(diags...)
@@ -167,6 +966,8 @@ func (o *orderResource) Create(ctx context.Context, request resource.CreateReque
 }
 
 func (o *orderResource) Read(ctx context.Context, request resource.ReadRequest, response *resource.ReadResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, o.client)
+
 	var state orderResourceModel
 	diags := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diags...)
@@ -174,7 +975,15 @@ func (o *orderResource) Read(ctx context.Context, request resource.ReadRequest,
 		return
 	}
 
-	order, err := o.client.GetOrder(state.ID.ValueString())
+	readTimeout, diags := state.Timeouts.Read(ctx, o.defaultTimeout())
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOrderTimeout(ctx, readTimeout)
+	defer cancel()
+
+	order, err := o.client.GetOrder(ctx, state.ID.ValueString())
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Error Reading HashiCups Order",
@@ -183,21 +992,82 @@ func (o *orderResource) Read(ctx context.Context, request resource.ReadRequest,
 		return
 	}
 
-	state.Items = []orderItemModel{}
+	// Only an import leaves the items of the prior state null.
+	imported := state.Items == nil
+	if imported {
+		o.verifyImportedItems(ctx, order, &response.Diagnostics)
+	}
+
+	state.LastUpdated = o.settings.reformatTimestamp(state.LastUpdated)
+
+	// Orders imported or created before managed_host existed belong to the
+	// host they were read from.
+	if state.ManagedHost.IsNull() {
+		state.ManagedHost = types.StringValue(o.client.HostURL)
+	}
+
+	if order.ExternalID != "" {
+		state.ExternalID = types.StringValue(order.ExternalID)
+	}
+
+	// A derived code is kept once set, even though the items change.
+	if order.ConfirmationCode != "" || state.ConfirmationCode.IsNull() {
+		state.ConfirmationCode = types.StringValue(orderConfirmationCode(order))
+	}
+
+	// Keep the configured formatting unless the scheduled instant changed.
+	if order.ScheduledFor != nil {
+		prior, err := time.Parse(time.RFC3339, state.ScheduledFor.ValueString())
+		if err != nil || !prior.Equal(*order.ScheduledFor) {
+			state.ScheduledFor = types.StringValue(order.ScheduledFor.Format(time.RFC3339))
+		}
+	}
+
+	// Always take the quantities from the server so out-of-band changes
+	// show up as drift in the next plan.
+	for i, item := range state.Items {
+		if i < len(order.Items) && item.Quantity.ValueInt64() != int64(order.Items[i].Quantity) {
+			tflog.Info(ctx, "Detected out-of-band HashiCups order quantity change", map[string]any{
+				"id":           state.ID.ValueString(),
+				"item":         i,
+				"old_quantity": item.Quantity.ValueInt64(),
+				"new_quantity": order.Items[i].Quantity,
+			})
+		}
+	}
+
+	// Log catalog price changes for auditing; the refreshed prices are kept.
+	priorPrices := make(map[int64]types.Float64, len(state.Items))
+	for _, item := range state.Items {
+		priorPrices[item.Coffee.ID.ValueInt64()] = item.Coffee.Price
+	}
 	for _, item := range order.Items {
-		state.Items = append(state.Items, orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.Int64Value(int64(item.Coffee.ID)),
-				Name:        types.StringValue(item.Coffee.Name),
-				Teaser:      types.StringValue(item.Coffee.Teaser),
-				Description: types.StringValue(item.Coffee.Description),
-				Price:       types.Float64Value(item.Coffee.Price),
-				Image:       types.StringValue(item.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(item.Quantity)),
-		})
+		prior, ok := priorPrices[int64(item.Coffee.ID)]
+		if ok && !prior.IsNull() && !prior.IsUnknown() && prior.ValueFloat64() != item.Coffee.Price {
+			tflog.Info(ctx, "Detected HashiCups coffee price change", map[string]any{
+				"id":        state.ID.ValueString(),
+				"coffee_id": item.Coffee.ID,
+				"old_price": prior.ValueFloat64(),
+				"new_price": item.Coffee.Price,
+			})
+		}
 	}
 
+	// Items from configuration or from_coffees are planned in that order,
+	// which Create and Update keep, so only imported items are sorted.
+	items := order.Items
+	if imported {
+		items = sortOrderItems(items, o.settings.orderItemSort)
+	}
+	prior := state.Items
+	state.Items = []orderItemModel{}
+	for _, item := range items {
+		state.Items = append(state.Items, newOrderItemModel(item))
+	}
+	keepItemNotes(state.Items, prior)
+	state.setTotals()
+	state.PrepMinutes = o.estimatedPrepMinutes(ctx, state.Items)
+
 	diags = response.State.Set(ctx, &state)
 	response.Diagnostics.Append(diags...)
 	if response.Diagnostics.HasError() {
@@ -205,14 +1075,217 @@ func (o *orderResource) Read(ctx context.Context, request resource.ReadRequest,
 	}
 }
 
+// sortOrderItems returns a copy of items in the order_item_sort mode. Items
+// with equal keys keep their API order.
+func sortOrderItems(items []OrderItem, mode string) []OrderItem {
+	sorted := append([]OrderItem(nil), items...)
+
+	switch mode {
+	case orderItemSortByCoffeeID:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Coffee.ID < sorted[j].Coffee.ID })
+	case orderItemSortByName:
+		sort.SliceStable(sorted, func(i, j int) bool { return sorted[i].Coffee.Name < sorted[j].Coffee.Name })
+	}
+
+	return sorted
+}
+
+// orderedBy returns the username of the authenticated user, or null with a
+// warning when it cannot be read.
+func (o *orderResource) orderedBy(ctx context.Context, diags *diag.Diagnostics) types.String {
+	user, err := o.client.GetMe(ctx)
+	if err != nil {
+		diags.AddWarning(
+			"Unable to Read HashiCups User",
+			"The order was created but ordered_by could not be set: "+err.Error(),
+		)
+		return types.StringNull()
+	}
+	if user.Username == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(user.Username)
+}
+
+// estimatedPrepMinutes combines the catalog prep time of each item's coffee,
+// multiplied by its quantity, as set by the provider prep_time_mode. It is
+// null when the catalog cannot be read or a coffee has no prep time.
+func (o *orderResource) estimatedPrepMinutes(ctx context.Context, items []orderItemModel) types.Int64 {
+	if o.catalog == nil {
+		return types.Int64Null()
+	}
+
+	coffees, err := o.catalog.coffeesByID(ctx)
+	if err != nil {
+		tflog.Warn(ctx, "Unable to read the coffee catalog, leaving estimated_prep_minutes null", map[string]any{"error": err.Error()})
+		return types.Int64Null()
+	}
+
+	var minutes int64
+	for _, item := range items {
+		coffee, ok := coffees[item.Coffee.ID.ValueInt64()]
+		if !ok || coffee.PrepMinutes == nil {
+			return types.Int64Null()
+		}
+
+		itemMinutes := int64(*coffee.PrepMinutes) * item.Quantity.ValueInt64()
+		if o.settings.prepTimeMode == prepTimeModeMax {
+			minutes = max(minutes, itemMinutes)
+		} else {
+			minutes += itemMinutes
+		}
+	}
+
+	return types.Int64Value(minutes)
+}
+
+// defaultOrderOperationTimeout bounds order operations when neither the
+// timeouts block nor the provider default_order_timeout sets a duration.
+const defaultOrderOperationTimeout = 30 * time.Second
+
+// defaultTimeout returns the duration of order operations whose timeouts
+// block does not set one.
+func (o *orderResource) defaultTimeout() time.Duration {
+	if o.settings.defaultOrderTimeout > 0 {
+		return o.settings.defaultOrderTimeout
+	}
+
+	return defaultOrderOperationTimeout
+}
+
+// withOrderTimeout bounds ctx by timeout, the duration from the timeouts
+// block or defaultTimeout. A zero timeout leaves ctx without a deadline.
+func withOrderTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
+
+// addOrderItemBatches adds items after the first created ones to a new
+// order, at most createBatchSize more per request. Each request sends all
+// items so far, since an update replaces the items of the order. It returns
+// the number of items the order holds.
+func (o *orderResource) addOrderItemBatches(ctx context.Context, orderID string, items []OrderItem, created int) (int, error) {
+	for created < len(items) {
+		next := min(created+o.settings.createBatchSize, len(items))
+		if _, err := o.client.UpdateOrder(ctx, orderID, items[:next]); err != nil {
+			return created, err
+		}
+		tflog.Debug(ctx, "Added HashiCups order item batch", map[string]any{"id": orderID, "items": next, "total_items": len(items)})
+		created = next
+	}
+
+	return created, nil
+}
+
+// orderItemChanges describes how the confirmed items differ from the
+// requested ones, comparing items by position.
+func orderItemChanges(requested, confirmed []OrderItem) []string {
+	var changes []string
+	for i, item := range requested {
+		if i >= len(confirmed) {
+			changes = append(changes, fmt.Sprintf("- item %d (coffee ID %d) was dropped", i, item.Coffee.ID))
+			continue
+		}
+
+		got := confirmed[i]
+		switch {
+		case got.Coffee.ID != item.Coffee.ID:
+			changes = append(changes, fmt.Sprintf("- item %d coffee ID %d was replaced with coffee ID %d", i, item.Coffee.ID, got.Coffee.ID))
+		case got.Quantity != item.Quantity:
+			changes = append(changes, fmt.Sprintf("- item %d (coffee ID %d) quantity %d was changed to %d", i, item.Coffee.ID, item.Quantity, got.Quantity))
+		}
+	}
+
+	for i := len(requested); i < len(confirmed); i++ {
+		changes = append(changes, fmt.Sprintf("- item %d (coffee ID %d) was added", i, confirmed[i].Coffee.ID))
+	}
+
+	return changes
+}
+
+// orderItemsChanged reports whether the planned items differ from the prior
+// items in coffee, quantity, or note, comparing items by position.
+func orderItemsChanged(planned, prior []orderItemModel) bool {
+	if len(planned) != len(prior) {
+		return true
+	}
+
+	for i, item := range planned {
+		if !item.Coffee.ID.Equal(prior[i].Coffee.ID) || !item.Quantity.Equal(prior[i].Quantity) || !item.Note.Equal(prior[i].Note) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// verifyImportedItems warns about items of an imported order whose coffee
+// cannot be resolved from the catalog, such as discontinued coffees.
+func (o *orderResource) verifyImportedItems(ctx context.Context, order *Order, diags *diag.Diagnostics) {
+	coffees, err := o.client.GetCoffees(ctx)
+	if err != nil {
+		diags.AddWarning(
+			"Unable to Verify Imported HashiCups Order",
+			"Could not read the coffee catalog, skipping the item check: "+err.Error(),
+		)
+		return
+	}
+
+	catalog := make(map[int]bool, len(coffees))
+	for _, coffee := range coffees {
+		catalog[coffee.ID] = true
+	}
+
+	var unresolved []string
+	for i, item := range order.Items {
+		if item.Coffee.Name == "" || !catalog[item.Coffee.ID] {
+			unresolved = append(unresolved, fmt.Sprintf("item %d (coffee ID %d)", i, item.Coffee.ID))
+		}
+	}
+
+	if len(unresolved) > 0 {
+		diags.AddWarning(
+			"Imported HashiCups Order Has Unresolved Coffees",
+			fmt.Sprintf("The coffees of %s could not be resolved from the catalog and may have been discontinued. "+
+				"Their computed coffee attributes may be empty, and updating the order may fail.", strings.Join(unresolved, ", ")),
+		)
+	}
+}
+
 func (o *orderResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
-	// Retrieve values from plan
-	var plan orderResourceModel
+	defer addDeprecationWarnings(&resp.Diagnostics, o.client)
+
+	if o.settings.readOnly {
+		addReadOnlyError(&resp.Diagnostics, "update the order")
+		return
+	}
+
+	if addOrderingHoursError(&resp.Diagnostics, o.settings, "update the order") {
+		return
+	}
+
+	// Retrieve values from plan and prior state
+	var plan, state orderResourceModel
 	diags := req.Plan.Get(ctx, &plan)
 	resp.Diagnostics.Append(diags...)
+	diags = req.State.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	itemsChanged := orderItemsChanged(plan.Items, state.Items)
+
+	updateTimeout, diags := plan.Timeouts.Update(ctx, o.defaultTimeout())
+	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
 		return
 	}
+	ctx, cancel := withOrderTimeout(ctx, updateTimeout)
+	defer cancel()
 
 	// Generate API request body from plan
 	var hashicupsItems []OrderItem
@@ -222,22 +1295,24 @@ func (o *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 				ID: int(item.Coffee.ID.ValueInt64()),
 			},
 			Quantity: int(item.Quantity.ValueInt64()),
+			Note:     item.Note.ValueString(),
 		})
 	}
 
 	// Update existing order
-	_, err := o.client.UpdateOrder(plan.ID.ValueString(), hashicupsItems)
+	_, err := o.client.UpdateOrder(ctx, plan.ID.ValueString(), hashicupsItems)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		addOrderAPIError(&resp.Diagnostics,
 			"Error Updating HashiCups Order",
 			"Could not update order, unexpected error: "+err.Error(),
+			err,
 		)
 		return
 	}
 
 	// Fetch updated items from GetOrder as UpdateOrder items are not
 	// populated.
-	order, err := o.client.GetOrder(plan.ID.ValueString())
+	order, err := o.client.GetOrder(ctx, plan.ID.ValueString())
 	if err != nil {
 		resp.Diagnostics.AddError(
 			"Error Reading HashiCups Order",
@@ -247,21 +1322,29 @@ func (o *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 	}
 
 	// Update resource state with updated items and timestamp
+	planned := plan.Items
 	plan.Items = []orderItemModel{}
 	for _, item := range order.Items {
-		plan.Items = append(plan.Items, orderItemModel{
-			Coffee: orderItemCoffeeModel{
-				ID:          types.Int64Value(int64(item.Coffee.ID)),
-				Name:        types.StringValue(item.Coffee.Name),
-				Teaser:      types.StringValue(item.Coffee.Teaser),
-				Description: types.StringValue(item.Coffee.Description),
-				Price:       types.Float64Value(item.Coffee.Price),
-				Image:       types.StringValue(item.Coffee.Image),
-			},
-			Quantity: types.Int64Value(int64(item.Quantity)),
-		})
+		plan.Items = append(plan.Items, newOrderItemModel(item))
+	}
+	keepItemNotes(plan.Items, planned)
+	plan.setTotals()
+	plan.PrepMinutes = o.estimatedPrepMinutes(ctx, plan.Items)
+	plan.LastUpdated = state.LastUpdated
+	if itemsChanged || state.LastUpdated.IsNull() {
+		plan.LastUpdated = types.StringValue(o.settings.formatTimestamp(o.settings.currentTime()))
+	}
+	// Only orders without a recorded creator, such as imported ones, plan
+	// an unknown value.
+	if plan.OrderedBy.IsUnknown() {
+		plan.OrderedBy = types.StringNull()
+	}
+	if plan.ManagedHost.IsUnknown() {
+		plan.ManagedHost = types.StringValue(o.client.HostURL)
+	}
+	if plan.ConfirmationCode.IsUnknown() {
+		plan.ConfirmationCode = types.StringValue(orderConfirmationCode(order))
 	}
-	plan.LastUpdated = types.StringValue(time.Now().Format(time.RFC850))
 
 	diags = resp.State.Set(ctx, plan)
 	resp.Diagnostics.Append(diags...)
@@ -271,6 +1354,13 @@ func (o *orderResource) Update(ctx context.Context, req resource.UpdateRequest,
 }
 
 func (o *orderResource) Delete(ctx context.Context, request resource.DeleteRequest, response *resource.DeleteResponse) {
+	defer addDeprecationWarnings(&response.Diagnostics, o.client)
+
+	if o.settings.readOnly {
+		addReadOnlyError(&response.Diagnostics, "delete the order")
+		return
+	}
+
 	var state orderResourceModel
 	diags := request.State.Get(ctx, &state)
 	response.Diagnostics.Append(diags...)
@@ -278,7 +1368,15 @@ func (o *orderResource) Delete(ctx context.Context, request resource.DeleteReque
 		return
 	}
 
-	err := o.client.DeleteOrder(state.ID.ValueString())
+	deleteTimeout, diags := state.Timeouts.Delete(ctx, o.defaultTimeout())
+	response.Diagnostics.Append(diags...)
+	if response.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := withOrderTimeout(ctx, deleteTimeout)
+	defer cancel()
+
+	err := o.client.DeleteOrder(ctx, state.ID.ValueString())
 	if err != nil {
 		response.Diagnostics.AddError(
 			"Error Deleting HashiCups Order",
@@ -293,9 +1391,112 @@ func (o *orderResource) Configure(ctx context.Context, request resource.Configur
 		return
 	}
 
-	o.client = request.ProviderData.(*Client)
+	data := request.ProviderData.(*providerData)
+	o.client = data.client
+	o.settings = data.settings
+	o.catalog = data.catalog
+}
+
+// orderConfirmationCode returns the confirmation code of order. Without one
+// from the API, it is derived from a hash of the order ID and items, such as
+// HC-1F3A9C2E.
+func orderConfirmationCode(order *Order) string {
+	if order.ConfirmationCode != "" {
+		return order.ConfirmationCode
+	}
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%d", order.ID)
+	for _, item := range order.Items {
+		fmt.Fprintf(h, ";%d:%d", item.Coffee.ID, item.Quantity)
+	}
+
+	return "HC-" + strings.ToUpper(hex.EncodeToString(h.Sum(nil))[:8])
+}
+
+// newOrderItemModel maps an API order item to its schema data.
+func newOrderItemModel(item OrderItem) orderItemModel {
+	return orderItemModel{
+		Coffee: orderItemCoffeeModel{
+			ID:          types.Int64Value(int64(item.Coffee.ID)),
+			Name:        types.StringValue(item.Coffee.Name),
+			Teaser:      types.StringValue(item.Coffee.Teaser),
+			Description: types.StringValue(item.Coffee.Description),
+			Price:       types.Float64Value(item.Coffee.Price),
+			Image:       types.StringValue(item.Coffee.Image),
+		},
+		Quantity:  types.Int64Value(int64(item.Quantity)),
+		Note:      noteValue(item.Note),
+		LineTotal: types.Float64Value(item.Coffee.Price * float64(item.Quantity)),
+	}
+}
+
+// setTotals sets the total price and item count from the items.
+func (m *orderResourceModel) setTotals() {
+	totalPrice, itemCount := orderItemTotals(m.Items)
+
+	m.TotalPrice = types.Float64Value(totalPrice)
+	m.Total = types.Float64Value(totalPrice)
+	m.ItemCount = types.Int64Value(itemCount)
+}
+
+// orderItemTotals returns the summed line totals and quantities of items.
+func orderItemTotals(items []orderItemModel) (float64, int64) {
+	var totalPrice float64
+	var itemCount int64
+	for _, item := range items {
+		totalPrice += item.LineTotal.ValueFloat64()
+		itemCount += item.Quantity.ValueInt64()
+	}
+
+	return totalPrice, itemCount
+}
+
+// keepItemNotes copies the note of each prior item onto the item at the same
+// position with the same coffee when the API reported no note for it, as
+// servers without note support drop the field.
+func keepItemNotes(items, prior []orderItemModel) {
+	for i := range items {
+		if i >= len(prior) || !items[i].Note.IsNull() || !items[i].Coffee.ID.Equal(prior[i].Coffee.ID) {
+			continue
+		}
+		items[i].Note = prior[i].Note
+	}
+}
+
+// noteValue returns the item note, or null when the item has none.
+func noteValue(note string) types.String {
+	if note == "" {
+		return types.StringNull()
+	}
+
+	return types.StringValue(note)
+}
+
+// addOrderAPIError adds a diagnostic tailored to the API error code of err,
+// falling back to summary and detail for other errors.
+func addOrderAPIError(diags *diag.Diagnostics, summary, detail string, err error) {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		diags.AddError(summary, detail)
+		return
+	}
+
+	switch apiErr.Code {
+	case ErrorCodeCoffeeOutOfStock:
+		diags.AddAttributeError(
+			path.Root("items"),
+			"HashiCups Coffee Out of Stock",
+			"The order requests a coffee that is out of stock: "+apiErr.Message+". "+
+				"Reduce the item quantities or order a different coffee.",
+		)
+	default:
+		diags.AddError(summary, detail)
+	}
 }
 
+// ImportState imports an order by ID. The following Read warns about items
+// whose coffee cannot be resolved.
 func (o *orderResource) ImportState(ctx context.Context, request resource.ImportStateRequest, response *resource.ImportStateResponse) {
 	resource.ImportStatePassthroughID(ctx, path.Root("id"), request, response)
 }
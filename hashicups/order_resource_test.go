@@ -1,8 +1,30 @@
 package hashicups
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	fwresource "github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
+	"github.com/hashicorp/terraform-plugin-log/tflogtest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 )
 
@@ -80,3 +102,1983 @@ resource "hashicups_order" "test" {
 		},
 	})
 }
+
+func TestOrderResourceReadOnly(t *testing.T) {
+	ctx := context.Background()
+	o := &orderResource{}
+	o.Configure(ctx, fwresource.ConfigureRequest{
+		ProviderData: &providerData{settings: providerSettings{readOnly: true}},
+	}, &fwresource.ConfigureResponse{})
+
+	var createResp fwresource.CreateResponse
+	o.Create(ctx, fwresource.CreateRequest{}, &createResp)
+
+	var updateResp fwresource.UpdateResponse
+	o.Update(ctx, fwresource.UpdateRequest{}, &updateResp)
+
+	var deleteResp fwresource.DeleteResponse
+	o.Delete(ctx, fwresource.DeleteRequest{}, &deleteResp)
+
+	for name, diags := range map[string]diag.Diagnostics{
+		"create": createResp.Diagnostics,
+		"update": updateResp.Diagnostics,
+		"delete": deleteResp.Diagnostics,
+	} {
+		if !diags.HasError() || diags.Errors()[0].Summary() != "HashiCups Provider Is Read-Only" {
+			t.Errorf("%s: expected read-only error, got %v", name, diags)
+		}
+	}
+}
+
+// modifyTestOrderPlan runs ModifyPlan on o for a create with the given root
+// attribute values in the configuration and plan.
+func modifyTestOrderPlan(t *testing.T, o *orderResource, config, plan map[string]any) *fwresource.ModifyPlanResponse {
+	t.Helper()
+	ctx := context.Background()
+	s := testResourceSchema(t, o)
+
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s, Raw: testResourceValue(t, s, config)},
+		Plan:   tfsdk.Plan{Schema: s, Raw: testResourceValue(t, s, plan)},
+		State:  tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(ctx), nil)},
+	}
+	resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+	o.ModifyPlan(ctx, req, resp)
+
+	return resp
+}
+
+func TestOrderResourceModifyPlanFromCoffees(t *testing.T) {
+	ctx := context.Background()
+	o := &orderResource{}
+	itemsType := testResourceSchema(t, o).Attributes["items"].GetType().(types.ListType)
+
+	tests := map[string]struct {
+		fromCoffees   []int64
+		quantity      any
+		expectedItems []orderItemModel
+		expectError   bool
+	}{
+		"two coffee selection": {
+			fromCoffees: []int64{1, 2},
+			quantity:    int64(3),
+			expectedItems: []orderItemModel{
+				testUnknownOrderItem(1, 3),
+				testUnknownOrderItem(2, 3),
+			},
+		},
+		"default quantity": {
+			fromCoffees:   []int64{4},
+			quantity:      types.Int64Null(),
+			expectedItems: []orderItemModel{testUnknownOrderItem(4, 1)},
+		},
+		"empty selection": {
+			fromCoffees: []int64{},
+			quantity:    int64(1),
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]any{"from_coffees": test.fromCoffees, "quantity": test.quantity}
+			plan := map[string]any{
+				"from_coffees": test.fromCoffees,
+				"quantity":     test.quantity,
+				"id":           types.StringUnknown(),
+				"last_updated": types.StringUnknown(),
+				"items":        types.ListUnknown(itemsType.ElemType),
+			}
+
+			resp := modifyTestOrderPlan(t, o, config, plan)
+
+			if test.expectError {
+				if !resp.Diagnostics.HasError() {
+					t.Fatal("expected error, got none")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var items []orderItemModel
+			resp.Plan.GetAttribute(ctx, path.Root("items"), &items)
+			if !reflect.DeepEqual(items, test.expectedItems) {
+				t.Errorf("expected items %+v, got %+v", test.expectedItems, items)
+			}
+		})
+	}
+}
+
+// testUnknownOrderItem returns a planned order item whose computed coffee
+// attributes are not yet known.
+func testUnknownOrderItem(coffeeID, quantity int64) orderItemModel {
+	return orderItemModel{
+		Coffee: orderItemCoffeeModel{
+			ID:          types.Int64Value(coffeeID),
+			Name:        types.StringUnknown(),
+			Teaser:      types.StringUnknown(),
+			Description: types.StringUnknown(),
+			Price:       types.Float64Unknown(),
+			Image:       types.StringUnknown(),
+		},
+		Quantity:  types.Int64Value(quantity),
+		LineTotal: types.Float64Unknown(),
+	}
+}
+
+func TestOrderResourceModifyPlanCheckStock(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","stock":5},{"id":2,"name":"Packer Spiced Latte","stock":1},{"id":3,"name":"Vaulatte"}]`))
+	})
+	o := &orderResource{client: client, settings: providerSettings{checkStock: true}}
+
+	tests := map[string]struct {
+		items       []orderItemModel
+		expectError bool
+	}{
+		"within stock": {
+			items: []orderItemModel{testUnknownOrderItem(1, 5), testUnknownOrderItem(2, 1)},
+		},
+		"stock unknown": {
+			items: []orderItemModel{testUnknownOrderItem(3, 100)},
+		},
+		"exceeds stock": {
+			items:       []orderItemModel{testUnknownOrderItem(1, 2), testUnknownOrderItem(2, 3)},
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := map[string]any{"items": test.items}
+			resp := modifyTestOrderPlan(t, o, values, values)
+
+			if !test.expectError {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			if resp.Diagnostics.ErrorsCount() != 1 {
+				t.Fatalf("expected one error, got %v", resp.Diagnostics)
+			}
+			detail := resp.Diagnostics.Errors()[0].Detail()
+			if !strings.Contains(detail, "Item 1") || !strings.Contains(detail, "shortfall of 2") {
+				t.Errorf("expected error to name item 1 and its shortfall, got %q", detail)
+			}
+		})
+	}
+}
+
+func TestOrderResourceModifyPlanCatalogReads(t *testing.T) {
+	var reads int32
+	var fail bool
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&reads, 1)
+		if fail {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","price":200,"stock":5,"max_order_quantity":3}]`))
+	})
+
+	named := testUnknownOrderItem(0, 2)
+	named.Coffee.ID = types.Int64Null()
+	named.Coffee.Name = types.StringValue("HCP Aeropress")
+
+	tests := map[string]struct {
+		fail          bool
+		items         []orderItemModel
+		expectWarning bool
+	}{
+		"shared read":  {items: []orderItemModel{named}},
+		"failing read": {fail: true, items: []orderItemModel{testUnknownOrderItem(1, 2)}, expectWarning: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			atomic.StoreInt32(&reads, 0)
+			fail = test.fail
+			o := &orderResource{client: client, settings: providerSettings{checkStock: true}}
+			values := map[string]any{"items": test.items}
+
+			resp := modifyTestOrderPlan(t, o, values, values)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if got := atomic.LoadInt32(&reads); got != 1 {
+				t.Errorf("expected the catalog to be read once, got %d reads", got)
+			}
+			if got := resp.Diagnostics.WarningsCount(); (got == 1) != test.expectWarning || got > 1 {
+				t.Errorf("expected warning %t, got %v", test.expectWarning, resp.Diagnostics)
+			}
+		})
+	}
+
+	t.Run("no client", func(t *testing.T) {
+		o := &orderResource{settings: providerSettings{checkStock: true}}
+		values := map[string]any{"items": []orderItemModel{testUnknownOrderItem(1, 2)}}
+		if resp := modifyTestOrderPlan(t, o, values, values); resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+	})
+}
+
+func TestOrderResourceCreateOutOfStock(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusConflict)
+		_, _ = w.Write([]byte(`{"code":"coffee_out_of_stock","message":"Packer Spiced Latte is out of stock"}`))
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	plan := testPlan(t, s, &orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{testUnknownOrderItem(2, 1)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		LastUpdated: types.StringUnknown(),
+	})
+	resp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+
+	if resp.Diagnostics.ErrorsCount() != 1 {
+		t.Fatalf("expected one error, got %v", resp.Diagnostics)
+	}
+	d := resp.Diagnostics.Errors()[0]
+	if d.Summary() != "HashiCups Coffee Out of Stock" || !strings.Contains(d.Detail(), "Packer Spiced Latte is out of stock") {
+		t.Errorf("unexpected diagnostic: %s: %s", d.Summary(), d.Detail())
+	}
+}
+
+func TestOrderResourceModifyPlanScheduledFor(t *testing.T) {
+	o := &orderResource{settings: providerSettings{
+		now: func() time.Time { return time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC) },
+	}}
+
+	tests := map[string]struct {
+		scheduledFor string
+		expectError  string
+	}{
+		"future": {
+			scheduledFor: "2030-01-02T09:00:00Z",
+		},
+		"future with offset": {
+			scheduledFor: "2030-01-01T14:00:00+01:00",
+		},
+		"past": {
+			scheduledFor: "2029-12-31T09:00:00Z",
+			expectError:  "is not in the future",
+		},
+		"malformed": {
+			scheduledFor: "tomorrow at nine",
+			expectError:  "must be an RFC3339 timestamp",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := map[string]any{
+				"items":         []orderItemModel{testUnknownOrderItem(1, 1)},
+				"scheduled_for": test.scheduledFor,
+			}
+			resp := modifyTestOrderPlan(t, o, values, values)
+
+			if test.expectError == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			if resp.Diagnostics.ErrorsCount() != 1 || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), test.expectError) {
+				t.Errorf("expected error containing %q, got %v", test.expectError, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestOrderResourceCreateScheduled(t *testing.T) {
+	ctx := context.Background()
+	var scheduledFor string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "POST" {
+			scheduledFor = r.URL.Query().Get("scheduled_for")
+		}
+		_, _ = w.Write([]byte(`{"id":7,"items":[{"coffee":{"id":1,"name":"HCP Aeropress"},"quantity":1}],"scheduled_for":"2030-01-02T09:00:00Z"}`))
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	plan := testPlan(t, s, &orderResourceModel{
+		ID:           types.StringUnknown(),
+		Items:        []orderItemModel{testUnknownOrderItem(1, 1)},
+		FromCoffees:  types.ListNull(types.Int64Type),
+		Timeouts:     testOrderTimeouts(nil),
+		ScheduledFor: types.StringValue("2030-01-02T10:00:00+01:00"),
+		LastUpdated:  types.StringUnknown(),
+	})
+	resp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	if scheduledFor != "2030-01-02T10:00:00+01:00" {
+		t.Errorf("expected scheduled_for to be sent, got %q", scheduledFor)
+	}
+
+	// The API reports the same instant in UTC, so the configured value is
+	// kept on read.
+	readResp := &fwresource.ReadResponse{State: resp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: resp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", readResp.Diagnostics)
+	}
+
+	var state orderResourceModel
+	readResp.State.Get(ctx, &state)
+	if got := state.ScheduledFor.ValueString(); got != "2030-01-02T10:00:00+01:00" {
+		t.Errorf("expected scheduled_for to be kept on read, got %q", got)
+	}
+}
+
+func TestOrderResourceImportUnresolvedCoffee(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/coffees":
+			_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+		default:
+			_, _ = w.Write([]byte(`{"id":7,"items":[{"coffee":{"id":1,"name":"HCP Aeropress"},"quantity":1},{"coffee":{"id":9},"quantity":2}]}`))
+		}
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	importResp := &fwresource.ImportStateResponse{State: testState(t, s, nil)}
+	o.ImportState(ctx, fwresource.ImportStateRequest{ID: "7"}, importResp)
+	if importResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected import error: %v", importResp.Diagnostics)
+	}
+
+	readResp := &fwresource.ReadResponse{State: importResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: importResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", readResp.Diagnostics)
+	}
+
+	warnings := readResp.Diagnostics.Warnings()
+	if len(warnings) != 1 || !strings.Contains(warnings[0].Detail(), "item 1 (coffee ID 9)") || strings.Contains(warnings[0].Detail(), "item 0") {
+		t.Errorf("expected a warning for item 1 only, got %v", readResp.Diagnostics)
+	}
+
+	// Later reads of the order do not repeat the check.
+	refreshResp := &fwresource.ReadResponse{State: readResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: readResp.State}, refreshResp)
+	if refreshResp.Diagnostics.WarningsCount() != 0 {
+		t.Errorf("expected no warnings on refresh, got %v", refreshResp.Diagnostics)
+	}
+}
+
+// testOrderAPI is an in-memory order API.
+type testOrderAPI struct {
+	mu      sync.Mutex
+	orders  map[string]Order
+	nextID  int
+	creates int
+	updates int
+	// failUpdate fails the update with this number, counting from one.
+	failUpdate int
+	// confirmationCode is returned with each created order.
+	confirmationCode string
+	// userReads counts requests for the authenticated user.
+	userReads int
+	// failOrderReads fails requests for a single order.
+	failOrderReads bool
+	// dropNotes discards item notes, as servers without note support do.
+	dropNotes bool
+}
+
+// newTestOrderClient returns a client backed by api.
+func newTestOrderClient(t *testing.T, api *testOrderAPI) *Client {
+	t.Helper()
+
+	return newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		api.mu.Lock()
+		defer api.mu.Unlock()
+
+		id := strings.TrimPrefix(r.URL.Path, "/orders/")
+		switch {
+		case r.URL.Path == "/me":
+			api.userReads++
+			_, _ = w.Write([]byte(`{"id":1,"username":"education"}`))
+		case r.Method == "GET" && r.URL.Path == "/orders":
+			orders := []Order{}
+			for _, order := range api.orders {
+				if order.ExternalID == r.URL.Query().Get("external_id") {
+					orders = append(orders, order)
+				}
+			}
+			_ = json.NewEncoder(w).Encode(orders)
+		case r.Method == "POST" || r.Method == "PUT":
+			var items []OrderItem
+			if err := json.NewDecoder(r.Body).Decode(&items); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			for i := range items {
+				items[i].Coffee.Name = fmt.Sprintf("Coffee %d", items[i].Coffee.ID)
+				if api.dropNotes {
+					items[i].Note = ""
+				}
+			}
+
+			if r.Method == "PUT" {
+				api.updates++
+				if api.updates == api.failUpdate {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+			}
+
+			order := api.orders[id]
+			if r.Method == "POST" {
+				api.creates++
+				api.nextID++
+				id = strconv.Itoa(api.nextID)
+				order = Order{ID: api.nextID, ExternalID: r.URL.Query().Get("external_id"), ConfirmationCode: api.confirmationCode}
+			}
+			order.Items = items
+			api.orders[id] = order
+			_ = json.NewEncoder(w).Encode(order)
+		case r.Method == "GET":
+			order, ok := api.orders[id]
+			if api.failOrderReads {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			_ = json.NewEncoder(w).Encode(order)
+		}
+	})
+}
+
+func TestOrderResourceCreateBatches(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		batchSize     int
+		failUpdate    int
+		failReads     bool
+		expectUpdates int
+		expectError   string
+	}{
+		"single batch":         {batchSize: 5},
+		"multiple batches":     {batchSize: 2, expectUpdates: 2},
+		"failing middle batch": {batchSize: 2, failUpdate: 1, expectUpdates: 1, expectError: "only 2 of its 5 items"},
+		"failing read back":    {batchSize: 2, failReads: true, expectUpdates: 2, expectError: "was created with the planned items"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}, failUpdate: test.failUpdate, failOrderReads: test.failReads}
+			o := &orderResource{client: newTestOrderClient(t, api), settings: providerSettings{createBatchSize: test.batchSize}}
+			s := testResourceSchema(t, o)
+
+			var items []orderItemModel
+			for id := int64(1); id <= 5; id++ {
+				items = append(items, testUnknownOrderItem(id, id))
+			}
+			planned := orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       items,
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			}
+			resp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, resp)
+
+			if api.creates != 1 || api.updates != test.expectUpdates {
+				t.Errorf("expected 1 create and %d updates, got %d and %d", test.expectUpdates, api.creates, api.updates)
+			}
+
+			var id types.String
+			resp.State.GetAttribute(ctx, path.Root("id"), &id)
+			if id.ValueString() != "1" {
+				t.Fatalf("expected order ID 1 in state, got %s", id)
+			}
+
+			if test.expectError != "" {
+				errs := resp.Diagnostics.Errors()
+				if len(errs) != 1 || !strings.Contains(errs[0].Detail(), test.expectError) {
+					t.Fatalf("expected an error mentioning %q, got %v", test.expectError, resp.Diagnostics)
+				}
+				if got := len(api.orders["1"].Items); test.failUpdate > 0 && got != 2 {
+					t.Errorf("expected the order to keep 2 items, got %d", got)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var state orderResourceModel
+			resp.State.Get(ctx, &state)
+			if len(state.Items) != 5 || state.ItemCount.ValueInt64() != 15 {
+				t.Errorf("expected 5 items totalling 15, got %d items totalling %s", len(state.Items), state.ItemCount)
+			}
+			if len(resp.Diagnostics.Warnings()) != 0 {
+				t.Errorf("unexpected warnings: %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestOrderResourceExternalID(t *testing.T) {
+	ctx := context.Background()
+
+	create := func(t *testing.T, api *testOrderAPI, quantity int64) orderResourceModel {
+		t.Helper()
+		o := &orderResource{client: newTestOrderClient(t, api)}
+		s := testResourceSchema(t, o)
+
+		plan := testPlan(t, s, &orderResourceModel{
+			ID:          types.StringUnknown(),
+			Items:       []orderItemModel{testUnknownOrderItem(1, quantity)},
+			FromCoffees: types.ListNull(types.Int64Type),
+			Timeouts:    testOrderTimeouts(nil),
+			ExternalID:  types.StringValue("ticket-42"),
+			LastUpdated: types.StringUnknown(),
+		})
+		resp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+		o.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("create: %v", resp.Diagnostics)
+		}
+
+		var state orderResourceModel
+		resp.State.Get(ctx, &state)
+
+		return state
+	}
+
+	t.Run("create when absent", func(t *testing.T) {
+		api := &testOrderAPI{orders: map[string]Order{}}
+		state := create(t, api, 1)
+
+		if api.creates != 1 {
+			t.Errorf("expected one order to be created, got %d", api.creates)
+		}
+		if got := api.orders[state.ID.ValueString()].ExternalID; got != "ticket-42" {
+			t.Errorf("expected external ID to be sent, got %q", got)
+		}
+	})
+
+	t.Run("adopt when present", func(t *testing.T) {
+		api := &testOrderAPI{
+			orders: map[string]Order{"5": {ID: 5, ExternalID: "ticket-42", Items: []OrderItem{{Coffee: Coffee{ID: 2}, Quantity: 1}}}},
+			nextID: 5,
+		}
+		state := create(t, api, 3)
+
+		if api.creates != 0 {
+			t.Errorf("expected no order to be created, got %d", api.creates)
+		}
+		if state.ID.ValueString() != "5" {
+			t.Errorf("expected order 5 to be adopted, got %s", state.ID)
+		}
+		if items := api.orders["5"].Items; len(items) != 1 || items[0].Coffee.ID != 1 || items[0].Quantity != 3 {
+			t.Errorf("expected adopted order to be updated to the plan, got %+v", items)
+		}
+	})
+
+	t.Run("update adopted order", func(t *testing.T) {
+		api := &testOrderAPI{
+			orders: map[string]Order{"5": {ID: 5, ExternalID: "ticket-42"}},
+			nextID: 5,
+		}
+		state := create(t, api, 1)
+
+		o := &orderResource{client: newTestOrderClient(t, api)}
+		s := testResourceSchema(t, o)
+		prior := testState(t, s, &state)
+		state.Items = []orderItemModel{testUnknownOrderItem(1, 4)}
+		state.LastUpdated = types.StringUnknown()
+
+		resp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+		o.Update(ctx, fwresource.UpdateRequest{Plan: testPlan(t, s, &state), State: prior}, resp)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("update: %v", resp.Diagnostics)
+		}
+		if items := api.orders["5"].Items; len(items) != 1 || items[0].Quantity != 4 {
+			t.Errorf("expected adopted order to be updated, got %+v", items)
+		}
+	})
+}
+
+func TestOrderResourceItemNotes(t *testing.T) {
+	ctx := context.Background()
+
+	// Servers that drop notes must not make the configured notes disappear
+	// from state after apply or refresh.
+	for name, dropNotes := range map[string]bool{"notes supported": false, "notes dropped": true} {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}, dropNotes: dropNotes}
+			o := &orderResource{client: newTestOrderClient(t, api)}
+			s := testResourceSchema(t, o)
+
+			item := testUnknownOrderItem(1, 1)
+			item.Note = types.StringValue("extra hot")
+			planned := orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       []orderItemModel{item, testUnknownOrderItem(2, 1)},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			}
+
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create: %v", createResp.Diagnostics)
+			}
+
+			var state orderResourceModel
+			createResp.State.Get(ctx, &state)
+			if got := api.orders[state.ID.ValueString()].Items[0].Note; !dropNotes && got != "extra hot" {
+				t.Errorf("expected note to be sent, got %q", got)
+			}
+			if state.Items[0].Note.ValueString() != "extra hot" || !state.Items[1].Note.IsNull() {
+				t.Errorf("unexpected notes in state: %s, %s", state.Items[0].Note, state.Items[1].Note)
+			}
+
+			prior := testState(t, s, &state)
+			state.Items[0].Note = types.StringValue("oat milk")
+			state.LastUpdated = types.StringUnknown()
+			updateResp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+			o.Update(ctx, fwresource.UpdateRequest{Plan: testPlan(t, s, &state), State: prior}, updateResp)
+			if updateResp.Diagnostics.HasError() {
+				t.Fatalf("update: %v", updateResp.Diagnostics)
+			}
+			updateResp.State.Get(ctx, &state)
+			if got := state.Items[0].Note.ValueString(); got != "oat milk" {
+				t.Errorf("expected updated note in state, got %q", got)
+			}
+
+			readResp := &fwresource.ReadResponse{State: updateResp.State}
+			o.Read(ctx, fwresource.ReadRequest{State: updateResp.State}, readResp)
+			readResp.State.Get(ctx, &state)
+			if got := state.Items[0].Note.ValueString(); got != "oat milk" {
+				t.Errorf("expected updated note to be read back, got %q", got)
+			}
+		})
+	}
+}
+
+func TestOrderResourceItemNoteLength(t *testing.T) {
+	s := testResourceSchema(t, &orderResource{})
+	note := s.Attributes["items"].(rschema.ListNestedAttribute).NestedObject.Attributes["note"].(rschema.StringAttribute)
+
+	for name, test := range map[string]struct {
+		note        string
+		expectError bool
+	}{
+		"within limit": {note: strings.Repeat("a", maxOrderItemNoteLength)},
+		"too long":     {note: strings.Repeat("a", maxOrderItemNoteLength+1), expectError: true},
+	} {
+		t.Run(name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			for _, v := range note.Validators {
+				resp := &validator.StringResponse{}
+				v.ValidateString(context.Background(), validator.StringRequest{
+					Path:        path.Root("items").AtListIndex(0).AtName("note"),
+					ConfigValue: types.StringValue(test.note),
+				}, resp)
+				diags.Append(resp.Diagnostics...)
+			}
+
+			if diags.HasError() != test.expectError {
+				t.Errorf("expected error %t, got %v", test.expectError, diags)
+			}
+		})
+	}
+}
+
+func TestOrderResourceQuantityValidation(t *testing.T) {
+	s := testResourceSchema(t, &orderResource{})
+	attributes := map[string]rschema.Int64Attribute{
+		"items.quantity": s.Attributes["items"].(rschema.ListNestedAttribute).NestedObject.Attributes["quantity"].(rschema.Int64Attribute),
+		"quantity":       s.Attributes["quantity"].(rschema.Int64Attribute),
+	}
+
+	for attribute, quantity := range attributes {
+		for name, test := range map[string]struct {
+			quantity    int64
+			expectError bool
+		}{
+			"zero":     {quantity: 0, expectError: true},
+			"negative": {quantity: -2, expectError: true},
+			"one":      {quantity: 1},
+			"several":  {quantity: 12},
+		} {
+			t.Run(attribute+"/"+name, func(t *testing.T) {
+				var diags diag.Diagnostics
+				for _, v := range quantity.Validators {
+					resp := &validator.Int64Response{}
+					v.ValidateInt64(context.Background(), validator.Int64Request{
+						Path:        path.Root("items").AtListIndex(0).AtName("quantity"),
+						ConfigValue: types.Int64Value(test.quantity),
+					}, resp)
+					diags.Append(resp.Diagnostics...)
+				}
+
+				if diags.HasError() != test.expectError {
+					t.Errorf("expected error %t, got %v", test.expectError, diags)
+				}
+			})
+		}
+	}
+}
+
+func TestOrderResourceModifyPlanTotals(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"price":200},{"id":2,"price":150}]`))
+	})
+	o := &orderResource{client: client}
+
+	unknownCoffee := testUnknownOrderItem(0, 1)
+	unknownCoffee.Coffee.ID = types.Int64Unknown()
+
+	tests := map[string]struct {
+		items            []orderItemModel
+		expectKnown      bool
+		expectTotal      float64
+		expectCount      int64
+		expectLineTotals []float64
+	}{
+		"known coffees": {
+			items:            []orderItemModel{testUnknownOrderItem(1, 2), testUnknownOrderItem(2, 1)},
+			expectKnown:      true,
+			expectTotal:      550,
+			expectCount:      3,
+			expectLineTotals: []float64{400, 150},
+		},
+		"unknown coffee": {
+			items: []orderItemModel{testUnknownOrderItem(1, 2), unknownCoffee},
+		},
+		"coffee not in catalog": {
+			items: []orderItemModel{testUnknownOrderItem(9, 1)},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			resp := modifyTestOrderPlan(t, o,
+				map[string]any{"items": test.items},
+				map[string]any{
+					"items":       test.items,
+					"total_price": types.Float64Unknown(),
+					"total":       types.Float64Unknown(),
+					"item_count":  types.Int64Unknown(),
+				},
+			)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var plan orderResourceModel
+			resp.Plan.Get(ctx, &plan)
+
+			if !test.expectKnown {
+				if !plan.TotalPrice.IsUnknown() || !plan.Total.IsUnknown() || !plan.ItemCount.IsUnknown() {
+					t.Errorf("expected unknown totals, got %s, %s, and %s", plan.TotalPrice, plan.Total, plan.ItemCount)
+				}
+				return
+			}
+
+			if plan.TotalPrice.IsUnknown() || plan.TotalPrice.ValueFloat64() != test.expectTotal {
+				t.Errorf("expected total_price %v, got %s", test.expectTotal, plan.TotalPrice)
+			}
+			if plan.Total.IsUnknown() || plan.Total.ValueFloat64() != test.expectTotal {
+				t.Errorf("expected total %v, got %s", test.expectTotal, plan.Total)
+			}
+			if plan.ItemCount.IsUnknown() || plan.ItemCount.ValueInt64() != test.expectCount {
+				t.Errorf("expected item_count %d, got %s", test.expectCount, plan.ItemCount)
+			}
+			for i, item := range plan.Items {
+				if item.LineTotal.IsUnknown() || item.LineTotal.ValueFloat64() != test.expectLineTotals[i] {
+					t.Errorf("expected item %d line_total %v, got %s", i, test.expectLineTotals[i], item.LineTotal)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderResourceReadQuantityDrift(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	planned := orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		LastUpdated: types.StringUnknown(),
+	}
+	createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create: %v", createResp.Diagnostics)
+	}
+
+	// Change the quantity out of band.
+	var created orderResourceModel
+	createResp.State.Get(ctx, &created)
+	order := api.orders[created.ID.ValueString()]
+	order.Items[0].Quantity = 3
+	api.orders[created.ID.ValueString()] = order
+
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	var state orderResourceModel
+	readResp.State.Get(ctx, &state)
+	if got := state.Items[0].Quantity.ValueInt64(); got != 3 {
+		t.Errorf("expected refreshed quantity 3, got %d", got)
+	}
+	if state.Items[0].Quantity.Equal(planned.Items[0].Quantity) {
+		t.Error("expected refreshed state to differ from the configuration, producing a plan diff")
+	}
+}
+
+func TestOrderResourceReadNullItems(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"id":1,"items":null}`))
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	prior := testState(t, s, &orderResourceModel{
+		ID:          types.StringValue("1"),
+		Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+	})
+	readResp := &fwresource.ReadResponse{State: prior}
+	o.Read(ctx, fwresource.ReadRequest{State: prior}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	var state orderResourceModel
+	readResp.State.Get(ctx, &state)
+	if state.Items == nil || len(state.Items) != 0 {
+		t.Errorf("expected empty items, got %+v", state.Items)
+	}
+	if state.ItemCount.ValueInt64() != 0 {
+		t.Errorf("expected item_count 0, got %s", state.ItemCount)
+	}
+}
+
+func TestOrderResourceReadPriceDrift(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	planned := orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{testUnknownOrderItem(1, 2)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		LastUpdated: types.StringUnknown(),
+	}
+	createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create: %v", createResp.Diagnostics)
+	}
+
+	// Change the coffee price out of band.
+	var created orderResourceModel
+	createResp.State.Get(ctx, &created)
+	order := api.orders[created.ID.ValueString()]
+	order.Items[0].Coffee.Price = 250
+	api.orders[created.ID.ValueString()] = order
+
+	var output bytes.Buffer
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	o.Read(tflogtest.RootLogger(ctx, &output), fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	entries, err := tflogtest.MultilineJSONDecode(&output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var logged bool
+	for _, entry := range entries {
+		if entry["@message"] == "Detected HashiCups coffee price change" {
+			logged = entry["coffee_id"] == float64(1) && entry["old_price"] == float64(0) && entry["new_price"] == float64(250)
+		}
+	}
+	if !logged {
+		t.Errorf("expected a price change log entry for coffee 1, got %v", entries)
+	}
+
+	var state orderResourceModel
+	readResp.State.Get(ctx, &state)
+	if got := state.Total.ValueFloat64(); got != 500 {
+		t.Errorf("expected refreshed total 500, got %v", got)
+	}
+	if state.Total.Equal(created.Total) {
+		t.Error("expected the refreshed total to differ from the created one")
+	}
+}
+
+func TestOrderResourceModifyPlanCoffeeNames(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"},{"id":2,"name":"Vaulatte"},{"id":3,"name":"Vaulatte"}]`))
+	})
+	o := &orderResource{client: client}
+
+	namedItem := func(name string, id types.Int64) orderItemModel {
+		item := testUnknownOrderItem(0, 1)
+		item.Coffee.ID = id
+		item.Coffee.Name = types.StringValue(name)
+		return item
+	}
+
+	tests := map[string]struct {
+		name        string
+		configID    types.Int64
+		expectID    int64
+		expectError string
+	}{
+		"unique match":        {name: "HCP Aeropress", configID: types.Int64Null(), expectID: 1},
+		"no match":            {name: "Nomadicano", configID: types.Int64Null(), expectError: "Unknown HashiCups Coffee Name"},
+		"ambiguous":           {name: "Vaulatte", configID: types.Int64Null(), expectError: "Ambiguous HashiCups Coffee Name"},
+		"id and name":         {name: "Vaulatte", configID: types.Int64Value(3), expectID: 3},
+		"id and other name":   {name: "HCP Aeropress", configID: types.Int64Value(3), expectError: "Mismatched HashiCups Coffee Name"},
+		"id missing and name": {name: "Nomadicano", configID: types.Int64Value(4), expectID: 4},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			plannedID := test.configID
+			if plannedID.IsNull() {
+				plannedID = types.Int64Unknown()
+			}
+			resp := modifyTestOrderPlan(t, o,
+				map[string]any{"items": []orderItemModel{testUnknownOrderItem(2, 1), namedItem(test.name, test.configID)}},
+				map[string]any{"items": []orderItemModel{testUnknownOrderItem(2, 1), namedItem(test.name, plannedID)}},
+			)
+
+			if test.expectError != "" {
+				if resp.Diagnostics.ErrorsCount() != 1 {
+					t.Fatalf("expected one error, got %v", resp.Diagnostics)
+				}
+				d := resp.Diagnostics.Errors()[0]
+				if d.Summary() != test.expectError || !strings.Contains(d.Detail(), "Item 1") {
+					t.Errorf("expected %q naming item 1, got %s: %s", test.expectError, d.Summary(), d.Detail())
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			var plan orderResourceModel
+			resp.Plan.Get(ctx, &plan)
+			if got := plan.Items[1].Coffee.ID; got.IsUnknown() || got.ValueInt64() != test.expectID {
+				t.Errorf("expected coffee ID %d, got %s", test.expectID, got)
+			}
+		})
+	}
+}
+
+func TestOrderResourceCoffeeIDAndName(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	item := testUnknownOrderItem(3, 1)
+	item.Coffee.Name = types.StringValue("Coffee 3")
+	plan := testPlan(t, s, &orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{item},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		LastUpdated: types.StringUnknown(),
+	})
+
+	createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: plan}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create: %v", createResp.Diagnostics)
+	}
+
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	// The configured name must survive apply and refresh unchanged.
+	for step, state := range map[string]tfsdk.State{"create": createResp.State, "read": readResp.State} {
+		var model orderResourceModel
+		state.Get(ctx, &model)
+		if got := model.Items[0].Coffee; got.ID.ValueInt64() != 3 || got.Name.ValueString() != "Coffee 3" {
+			t.Errorf("%s: expected coffee 3 named %q, got %d named %s", step, "Coffee 3", got.ID.ValueInt64(), got.Name)
+		}
+	}
+}
+
+func TestOrderResourceReadItemSort(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string][]int64{
+		orderItemSortNone:       {3, 1, 2},
+		orderItemSortByCoffeeID: {1, 2, 3},
+		orderItemSortByName:     {2, 3, 1},
+	}
+
+	for mode, expected := range tests {
+		t.Run(mode, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{
+				"1": {ID: 1, Items: []OrderItem{
+					{Coffee: Coffee{ID: 3, Name: "Nomadicano"}, Quantity: 1},
+					{Coffee: Coffee{ID: 1, Name: "Vaulatte"}, Quantity: 1},
+					{Coffee: Coffee{ID: 2, Name: "Americano"}, Quantity: 1},
+				}},
+			}}
+			o := &orderResource{client: newTestOrderClient(t, api), settings: providerSettings{orderItemSort: mode}}
+			s := testResourceSchema(t, o)
+
+			// Only imported items, null in the prior state, are sorted.
+			for _, items := range [][]orderItemModel{nil, {}} {
+				prior := testState(t, s, &orderResourceModel{
+					ID:          types.StringValue("1"),
+					Items:       items,
+					FromCoffees: types.ListNull(types.Int64Type),
+					Timeouts:    testOrderTimeouts(nil),
+				})
+				resp := &fwresource.ReadResponse{State: prior}
+				o.Read(ctx, fwresource.ReadRequest{State: prior}, resp)
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("read: %v", resp.Diagnostics)
+				}
+
+				var state orderResourceModel
+				resp.State.Get(ctx, &state)
+				var got []int64
+				for _, item := range state.Items {
+					got = append(got, item.Coffee.ID.ValueInt64())
+				}
+				want := expected
+				if items != nil {
+					want = []int64{3, 1, 2}
+				}
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("expected coffee IDs %v, got %v", want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderResourceItemSortKeepsConfiguredOrder(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api), settings: providerSettings{orderItemSort: orderItemSortByCoffeeID}}
+	s := testResourceSchema(t, o)
+
+	createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &orderResourceModel{
+		ID:               types.StringUnknown(),
+		Items:            []orderItemModel{testUnknownOrderItem(3, 1), testUnknownOrderItem(1, 1), testUnknownOrderItem(2, 1)},
+		FromCoffees:      types.ListNull(types.Int64Type),
+		Timeouts:         testOrderTimeouts(nil),
+		LastUpdated:      types.StringUnknown(),
+		ConfirmationCode: types.StringUnknown(),
+	})}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create: %v", createResp.Diagnostics)
+	}
+
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	// Planning the unchanged configuration against the refreshed state must
+	// not reorder the items.
+	req := fwresource.ModifyPlanRequest{
+		Config: tfsdk.Config{Schema: s, Raw: testResourceValue(t, s, map[string]any{
+			"items": []orderItemModel{testUnknownOrderItem(3, 1), testUnknownOrderItem(1, 1), testUnknownOrderItem(2, 1)},
+		})},
+		Plan:  tfsdk.Plan{Schema: s, Raw: createResp.State.Raw},
+		State: readResp.State,
+	}
+	planResp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+	o.ModifyPlan(ctx, req, planResp)
+	if planResp.Diagnostics.HasError() {
+		t.Fatalf("plan: %v", planResp.Diagnostics)
+	}
+
+	var created, refreshed, planned orderResourceModel
+	createResp.State.Get(ctx, &created)
+	readResp.State.Get(ctx, &refreshed)
+	planResp.Plan.Get(ctx, &planned)
+	for i, want := range []int64{3, 1, 2} {
+		if got := refreshed.Items[i].Coffee.ID.ValueInt64(); got != want {
+			t.Errorf("expected refreshed item %d to be coffee %d, got %d", i, want, got)
+		}
+	}
+	if !reflect.DeepEqual(refreshed.Items, planned.Items) || !reflect.DeepEqual(created.Items, refreshed.Items) {
+		t.Errorf("expected no item changes, created %+v, refreshed %+v, planned %+v", created.Items, refreshed.Items, planned.Items)
+	}
+}
+
+func TestOrderResourceModifyPlanMaxOrderQuantity(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","max_order_quantity":3},{"id":2,"name":"Vaulatte"}]`))
+	})
+	o := &orderResource{client: client}
+
+	tests := map[string]struct {
+		items       []orderItemModel
+		expectError bool
+	}{
+		"within max": {
+			items: []orderItemModel{testUnknownOrderItem(1, 3)},
+		},
+		"no max": {
+			items: []orderItemModel{testUnknownOrderItem(2, 100)},
+		},
+		"exceeds max": {
+			items:       []orderItemModel{testUnknownOrderItem(2, 1), testUnknownOrderItem(1, 4)},
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			values := map[string]any{"items": test.items}
+			resp := modifyTestOrderPlan(t, o, values, values)
+
+			if !test.expectError {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+
+			if resp.Diagnostics.ErrorsCount() != 1 {
+				t.Fatalf("expected one error, got %v", resp.Diagnostics)
+			}
+			detail := resp.Diagnostics.Errors()[0].Detail()
+			if !strings.Contains(detail, "Item 1") || !strings.Contains(detail, "at most 3") {
+				t.Errorf("expected error to name item 1 and its maximum, got %q", detail)
+			}
+		})
+	}
+}
+
+func TestOrderResourceCreateAlteredByServer(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		// Clamp the second item to at most 2.
+		_, _ = w.Write([]byte(`{"id":1,"items":[
+			{"coffee":{"id":1,"name":"HCP Aeropress"},"quantity":1},
+			{"coffee":{"id":2,"name":"Vaulatte"},"quantity":2}
+		]}`))
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	plan := testPlan(t, s, &orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{testUnknownOrderItem(1, 1), testUnknownOrderItem(2, 5)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		LastUpdated: types.StringUnknown(),
+	})
+	resp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: plan}, resp)
+
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	if resp.Diagnostics.WarningsCount() != 1 {
+		t.Fatalf("expected one warning, got %v", resp.Diagnostics)
+	}
+	if detail := resp.Diagnostics.Warnings()[0].Detail(); !strings.Contains(detail, "item 1 (coffee ID 2) quantity 5 was changed to 2") {
+		t.Errorf("expected warning to name the clamped item, got %q", detail)
+	}
+
+	var state orderResourceModel
+	resp.State.Get(ctx, &state)
+	if got := state.Items[1].Quantity.ValueInt64(); got != 2 {
+		t.Errorf("expected confirmed quantity 2 in state, got %d", got)
+	}
+}
+
+// testOrderTimeouts returns an order timeouts block setting the given
+// operation durations, or a null block when there are none.
+func testOrderTimeouts(durations map[string]string) timeouts.Value {
+	attrTypes := map[string]attr.Type{
+		"create": types.StringType,
+		"read":   types.StringType,
+		"update": types.StringType,
+		"delete": types.StringType,
+	}
+	if len(durations) == 0 {
+		return timeouts.Value{Object: types.ObjectNull(attrTypes)}
+	}
+
+	values := map[string]attr.Value{
+		"create": types.StringNull(),
+		"read":   types.StringNull(),
+		"update": types.StringNull(),
+		"delete": types.StringNull(),
+	}
+	for operation, duration := range durations {
+		values[operation] = types.StringValue(duration)
+	}
+
+	return timeouts.Value{Object: types.ObjectValueMust(attrTypes, values)}
+}
+
+func TestOrderResourceDefaultOrderTimeout(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			_, _ = w.Write([]byte("Deleted order"))
+		case <-r.Context().Done():
+		}
+	})
+	o := &orderResource{client: client, settings: providerSettings{defaultOrderTimeout: 20 * time.Millisecond}}
+	s := testResourceSchema(t, o)
+
+	tests := map[string]struct {
+		timeouts    timeouts.Value
+		expectError bool
+	}{
+		"provider default": {
+			timeouts:    testOrderTimeouts(nil),
+			expectError: true,
+		},
+		"timeouts block": {
+			timeouts: testOrderTimeouts(map[string]string{"delete": "5s"}),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := testState(t, s, &orderResourceModel{
+				ID:          types.StringValue("1"),
+				Items:       []orderItemModel{},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    test.timeouts,
+			})
+			resp := &fwresource.DeleteResponse{State: state}
+			o.Delete(ctx, fwresource.DeleteRequest{State: state}, resp)
+
+			if !test.expectError {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), "deadline exceeded") {
+				t.Errorf("expected a deadline exceeded error, got %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestOrderResourceReadTimeout(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(100 * time.Millisecond):
+			_, _ = w.Write([]byte(`{"id":1}`))
+		case <-r.Context().Done():
+		}
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	if got := o.defaultTimeout(); got != defaultOrderOperationTimeout {
+		t.Errorf("expected default timeout %s, got %s", defaultOrderOperationTimeout, got)
+	}
+
+	tests := map[string]struct {
+		timeouts    timeouts.Value
+		expectError bool
+	}{
+		"default": {
+			timeouts: testOrderTimeouts(nil),
+		},
+		"read timeout": {
+			timeouts:    testOrderTimeouts(map[string]string{"read": "20ms"}),
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			state := testState(t, s, &orderResourceModel{
+				ID:          types.StringValue("1"),
+				Items:       []orderItemModel{},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    test.timeouts,
+			})
+			resp := &fwresource.ReadResponse{State: state}
+			o.Read(ctx, fwresource.ReadRequest{State: state}, resp)
+
+			if !test.expectError {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if !resp.Diagnostics.HasError() || !strings.Contains(resp.Diagnostics.Errors()[0].Detail(), "deadline exceeded") {
+				t.Errorf("expected a deadline exceeded error, got %v", resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestOrderResourceValidateCoffeeIDs(t *testing.T) {
+	ctx := context.Background()
+	var requests int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		_, _ = w.Write([]byte(`[{"id":1},{"id":2},{"id":3}]`))
+	})
+	catalog := &coffeeCatalog{client: client}
+	settings := providerSettings{validateCoffeeIDs: true}
+
+	tests := map[string]struct {
+		config      map[string]any
+		expectError string
+	}{
+		"valid items": {
+			config: map[string]any{"items": []orderItemModel{testUnknownOrderItem(1, 1), testUnknownOrderItem(3, 1)}},
+		},
+		"invalid items": {
+			config:      map[string]any{"items": []orderItemModel{testUnknownOrderItem(7, 1), testUnknownOrderItem(2, 1), testUnknownOrderItem(9, 1)}},
+			expectError: "7 (items[0]), 9 (items[2])",
+		},
+		"invalid from_coffees": {
+			config:      map[string]any{"from_coffees": []int64{1, 8}},
+			expectError: "8 (from_coffees[1])",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := &orderResource{client: client, settings: settings, catalog: catalog}
+			s := testResourceSchema(t, o)
+
+			resp := &fwresource.ValidateConfigResponse{}
+			o.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: s, Raw: testResourceValue(t, s, test.config)},
+			}, resp)
+
+			if test.expectError == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.ErrorsCount() != 1 {
+				t.Fatalf("expected one error, got %v", resp.Diagnostics)
+			}
+			if detail := resp.Diagnostics.Errors()[0].Detail(); !strings.Contains(detail, test.expectError) {
+				t.Errorf("expected error to list %s, got %q", test.expectError, detail)
+			}
+		})
+	}
+
+	if requests := atomic.LoadInt32(&requests); requests != 1 {
+		t.Errorf("expected the catalog to be read once, got %d requests", requests)
+	}
+}
+
+func TestOrderResourceValidateDuplicateCoffees(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		items        []orderItemModel
+		expectErrors []string
+	}{
+		"distinct coffees": {
+			items: []orderItemModel{testUnknownOrderItem(1, 1), testUnknownOrderItem(2, 3)},
+		},
+		"one duplicate": {
+			items:        []orderItemModel{testUnknownOrderItem(1, 1), testUnknownOrderItem(2, 1), testUnknownOrderItem(1, 2)},
+			expectErrors: []string{"Coffee ID 1 is listed in items[0], items[2]."},
+		},
+		"several duplicates": {
+			items: []orderItemModel{
+				testUnknownOrderItem(3, 1), testUnknownOrderItem(1, 1), testUnknownOrderItem(3, 1),
+				testUnknownOrderItem(1, 1), testUnknownOrderItem(3, 1),
+			},
+			expectErrors: []string{
+				"Coffee ID 3 is listed in items[0], items[2], items[4].",
+				"Coffee ID 1 is listed in items[1], items[3].",
+			},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := &orderResource{}
+			s := testResourceSchema(t, o)
+
+			resp := &fwresource.ValidateConfigResponse{}
+			o.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: s, Raw: testResourceValue(t, s, map[string]any{"items": test.items})},
+			}, resp)
+
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != len(test.expectErrors) {
+				t.Fatalf("expected %d errors, got %v", len(test.expectErrors), resp.Diagnostics)
+			}
+			for i, expected := range test.expectErrors {
+				if detail := errs[i].Detail(); !strings.HasPrefix(detail, expected) {
+					t.Errorf("expected error %d to start with %q, got %q", i, expected, detail)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderResourceOrderedBy(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	for i := 0; i < 2; i++ {
+		planned := orderResourceModel{
+			ID:          types.StringUnknown(),
+			Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+			FromCoffees: types.ListNull(types.Int64Type),
+			Timeouts:    testOrderTimeouts(nil),
+			OrderedBy:   types.StringUnknown(),
+			LastUpdated: types.StringUnknown(),
+		}
+		createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+		o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+		if createResp.Diagnostics.HasError() {
+			t.Fatalf("create: %v", createResp.Diagnostics)
+		}
+
+		var state orderResourceModel
+		createResp.State.Get(ctx, &state)
+		if got := state.OrderedBy.ValueString(); got != "education" {
+			t.Fatalf("expected ordered_by education, got %q", got)
+		}
+
+		prior := createResp.State
+		state.Items = []orderItemModel{testUnknownOrderItem(2, 3)}
+		state.LastUpdated = types.StringUnknown()
+		updateResp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+		o.Update(ctx, fwresource.UpdateRequest{Plan: testPlan(t, s, &state), State: prior}, updateResp)
+		if updateResp.Diagnostics.HasError() {
+			t.Fatalf("update: %v", updateResp.Diagnostics)
+		}
+
+		updateResp.State.Get(ctx, &state)
+		if got := state.OrderedBy.ValueString(); got != "education" {
+			t.Errorf("expected ordered_by to be kept on update, got %q", got)
+		}
+	}
+
+	if api.userReads != 1 {
+		t.Errorf("expected the user to be read once, got %d reads", api.userReads)
+	}
+}
+
+func TestOrderResourceCreatePartialApply(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{
+		orders:         map[string]Order{"5": {ID: 5, ExternalID: "ticket-42"}},
+		nextID:         5,
+		failOrderReads: true,
+	}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	planned := orderResourceModel{
+		ID:          types.StringUnknown(),
+		Items:       []orderItemModel{testUnknownOrderItem(1, 2)},
+		FromCoffees: types.ListNull(types.Int64Type),
+		Timeouts:    testOrderTimeouts(nil),
+		ExternalID:  types.StringValue("ticket-42"),
+		LastUpdated: types.StringUnknown(),
+	}
+	createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+	o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+	errs := createResp.Diagnostics.Errors()
+	if len(errs) != 1 || !strings.Contains(errs[0].Detail(), "was adopted and updated to the planned items") {
+		t.Fatalf("expected the read after adopting the order to fail, got %v", createResp.Diagnostics)
+	}
+
+	var created orderResourceModel
+	createResp.State.Get(ctx, &created)
+	if created.ID.ValueString() != "5" {
+		t.Fatalf("expected order ID 5 to be saved to state, got %s", created.ID)
+	}
+
+	// The next refresh recovers the rest of the order.
+	api.failOrderReads = false
+	readResp := &fwresource.ReadResponse{State: createResp.State}
+	o.Read(ctx, fwresource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+
+	var state orderResourceModel
+	readResp.State.Get(ctx, &state)
+	if len(state.Items) != 1 || state.Items[0].Quantity.ValueInt64() != 2 {
+		t.Errorf("expected the recovered order to have its items, got %+v", state.Items)
+	}
+}
+
+func TestOrderResourceOrderingHours(t *testing.T) {
+	ctx := context.Background()
+	window, err := parseOrderingWindow("09:00-17:00", time.UTC)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		now         time.Time
+		expectError bool
+	}{
+		"in hours":     {now: time.Date(2030, 1, 1, 9, 0, 0, 0, time.UTC)},
+		"before hours": {now: time.Date(2030, 1, 1, 8, 59, 0, 0, time.UTC), expectError: true},
+		"after hours":  {now: time.Date(2030, 1, 1, 17, 0, 0, 0, time.UTC), expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}}
+			o := &orderResource{
+				client:   newTestOrderClient(t, api),
+				settings: providerSettings{orderingHours: window, now: func() time.Time { return test.now }},
+			}
+			s := testResourceSchema(t, o)
+
+			plan := testPlan(t, s, &orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			})
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: plan}, createResp)
+
+			updateResp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+			o.Update(ctx, fwresource.UpdateRequest{Plan: plan, State: createResp.State}, updateResp)
+
+			if !test.expectError {
+				if createResp.Diagnostics.HasError() {
+					t.Errorf("create: unexpected error: %v", createResp.Diagnostics)
+				}
+				if api.creates != 1 {
+					t.Errorf("expected the order to be created, got %d creates", api.creates)
+				}
+				return
+			}
+
+			for name, diags := range map[string]diag.Diagnostics{
+				"create": createResp.Diagnostics,
+				"update": updateResp.Diagnostics,
+			} {
+				if !diags.HasError() || diags.Errors()[0].Summary() != "Outside HashiCups Ordering Hours" {
+					t.Errorf("%s: expected ordering hours error, got %v", name, diags)
+				}
+			}
+			if api.creates != 0 {
+				t.Errorf("expected no order to be created, got %d creates", api.creates)
+			}
+		})
+	}
+}
+
+func TestOrderResourceEstimatedPrepMinutes(t *testing.T) {
+	ctx := context.Background()
+	catalogClient := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"prep_minutes":3},{"id":2,"prep_minutes":5},{"id":3}]`))
+	})
+
+	tests := map[string]struct {
+		mode   string
+		items  []orderItemModel
+		expect types.Int64
+	}{
+		"sum": {
+			mode:   prepTimeModeSum,
+			items:  []orderItemModel{testUnknownOrderItem(1, 4), testUnknownOrderItem(2, 1)},
+			expect: types.Int64Value(17),
+		},
+		"default is sum": {
+			items:  []orderItemModel{testUnknownOrderItem(1, 4), testUnknownOrderItem(2, 1)},
+			expect: types.Int64Value(17),
+		},
+		"max": {
+			mode:   prepTimeModeMax,
+			items:  []orderItemModel{testUnknownOrderItem(1, 4), testUnknownOrderItem(2, 1)},
+			expect: types.Int64Value(12),
+		},
+		"coffee without prep time": {
+			mode:   prepTimeModeSum,
+			items:  []orderItemModel{testUnknownOrderItem(1, 1), testUnknownOrderItem(3, 1)},
+			expect: types.Int64Null(),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}}
+			o := &orderResource{
+				client:   newTestOrderClient(t, api),
+				settings: providerSettings{prepTimeMode: test.mode},
+				catalog:  &coffeeCatalog{client: catalogClient},
+			}
+			s := testResourceSchema(t, o)
+
+			plan := testPlan(t, s, &orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       test.items,
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			})
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: plan}, createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create: %v", createResp.Diagnostics)
+			}
+
+			var state orderResourceModel
+			createResp.State.Get(ctx, &state)
+			if !state.PrepMinutes.Equal(test.expect) {
+				t.Errorf("expected estimated_prep_minutes %s, got %s", test.expect, state.PrepMinutes)
+			}
+		})
+	}
+}
+
+func TestOrderResourceModifyPlanHostChange(t *testing.T) {
+	ctx := context.Background()
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress","price":200}]`))
+	})
+	o := &orderResource{client: client}
+	s := testResourceSchema(t, o)
+
+	tests := map[string]struct {
+		managedHost   types.String
+		expectReplace bool
+	}{
+		"same host":            {managedHost: types.StringValue(client.HostURL)},
+		"same host with slash": {managedHost: types.StringValue(client.HostURL + "/")},
+		"unrecorded host":      {managedHost: types.StringNull()},
+		"different host":       {managedHost: types.StringValue("http://hashicups.example.com"), expectReplace: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			item := testUnknownOrderItem(1, 2)
+			item.Coffee.Name = types.StringValue("HCP Aeropress")
+			item.LineTotal = types.Float64Value(400)
+			prior := orderResourceModel{
+				ID:          types.StringValue("1"),
+				Items:       []orderItemModel{item},
+				FromCoffees: types.ListNull(types.Int64Type),
+				ManagedHost: test.managedHost,
+				Timeouts:    testOrderTimeouts(nil),
+			}
+			plan := testPlan(t, s, &prior)
+
+			req := fwresource.ModifyPlanRequest{
+				Config: tfsdk.Config{Schema: s, Raw: plan.Raw},
+				Plan:   plan,
+				State:  testState(t, s, &prior),
+			}
+			resp := &fwresource.ModifyPlanResponse{Plan: req.Plan}
+			o.ModifyPlan(ctx, req, resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			replace := len(resp.RequiresReplace) == 1 && resp.RequiresReplace[0].Equal(path.Root("managed_host"))
+			if replace != test.expectReplace {
+				t.Errorf("expected replace %t, got requires replace %v", test.expectReplace, resp.RequiresReplace)
+			}
+
+			var managedHost types.String
+			resp.Plan.GetAttribute(ctx, path.Root("managed_host"), &managedHost)
+			if test.expectReplace && managedHost.ValueString() != client.HostURL {
+				t.Errorf("expected planned managed_host %q, got %s", client.HostURL, managedHost)
+			}
+		})
+	}
+}
+
+func TestOrderResourceUpdateLastUpdated(t *testing.T) {
+	ctx := context.Background()
+	api := &testOrderAPI{orders: map[string]Order{}}
+	o := &orderResource{client: newTestOrderClient(t, api)}
+	s := testResourceSchema(t, o)
+
+	tests := map[string]struct {
+		quantity      int64
+		expectChanged bool
+	}{
+		"identical items": {quantity: 1},
+		"changed items":   {quantity: 2, expectChanged: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			})}, createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create: %v", createResp.Diagnostics)
+			}
+
+			// Backdate the prior timestamp so a rewrite is always visible.
+			var state orderResourceModel
+			createResp.State.Get(ctx, &state)
+			state.LastUpdated = types.StringValue("Monday, 01-Jan-24 00:00:00 UTC")
+			prior := testState(t, s, &state)
+
+			state.Items[0].Quantity = types.Int64Value(test.quantity)
+			state.LastUpdated = types.StringUnknown()
+			updateResp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+			o.Update(ctx, fwresource.UpdateRequest{Plan: testPlan(t, s, &state), State: prior}, updateResp)
+			if updateResp.Diagnostics.HasError() {
+				t.Fatalf("update: %v", updateResp.Diagnostics)
+			}
+
+			var updated orderResourceModel
+			updateResp.State.Get(ctx, &updated)
+			if changed := updated.LastUpdated.ValueString() != "Monday, 01-Jan-24 00:00:00 UTC"; changed != test.expectChanged {
+				t.Errorf("expected last_updated changed %t, got %s", test.expectChanged, updated.LastUpdated)
+			}
+		})
+	}
+}
+
+func TestOrderResourceConfirmationCode(t *testing.T) {
+	ctx := context.Background()
+
+	tests := map[string]struct {
+		apiCode string
+	}{
+		"from api": {apiCode: "BLUE-42"},
+		"derived":  {},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}, confirmationCode: test.apiCode}
+			o := &orderResource{client: newTestOrderClient(t, api)}
+			s := testResourceSchema(t, o)
+
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &orderResourceModel{
+				ID:               types.StringUnknown(),
+				Items:            []orderItemModel{testUnknownOrderItem(1, 1)},
+				FromCoffees:      types.ListNull(types.Int64Type),
+				Timeouts:         testOrderTimeouts(nil),
+				LastUpdated:      types.StringUnknown(),
+				ConfirmationCode: types.StringUnknown(),
+			})}, createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create: %v", createResp.Diagnostics)
+			}
+
+			var state orderResourceModel
+			createResp.State.Get(ctx, &state)
+			code := state.ConfirmationCode.ValueString()
+			switch {
+			case test.apiCode != "" && code != test.apiCode:
+				t.Fatalf("expected confirmation code %q, got %q", test.apiCode, code)
+			case test.apiCode == "" && (!strings.HasPrefix(code, "HC-") || len(code) != 11):
+				t.Fatalf("expected a derived confirmation code, got %q", code)
+			}
+			if derived := orderConfirmationCode(&Order{ID: 1, Items: api.orders["1"].Items}); test.apiCode == "" && derived != code {
+				t.Errorf("expected the derived code to be stable, got %q and %q", code, derived)
+			}
+
+			// Updating the items keeps the code planned from state.
+			prior := testState(t, s, &state)
+			state.Items[0].Quantity = types.Int64Value(3)
+			updateResp := &fwresource.UpdateResponse{State: testState(t, s, nil)}
+			o.Update(ctx, fwresource.UpdateRequest{Plan: testPlan(t, s, &state), State: prior}, updateResp)
+			if updateResp.Diagnostics.HasError() {
+				t.Fatalf("update: %v", updateResp.Diagnostics)
+			}
+
+			readResp := &fwresource.ReadResponse{State: updateResp.State}
+			o.Read(ctx, fwresource.ReadRequest{State: updateResp.State}, readResp)
+			if readResp.Diagnostics.HasError() {
+				t.Fatalf("read: %v", readResp.Diagnostics)
+			}
+
+			var refreshed orderResourceModel
+			readResp.State.Get(ctx, &refreshed)
+			if got := refreshed.ConfirmationCode.ValueString(); got != code {
+				t.Errorf("expected confirmation code %q after update and refresh, got %q", code, got)
+			}
+		})
+	}
+}
+
+func TestOrderResourceTimestampFormat(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2030, 1, 2, 9, 30, 15, 0, time.UTC)
+
+	tests := map[string]struct {
+		timestampFormat string
+		expected        string
+	}{
+		"default":       {expected: "2030-01-02T09:30:15Z"},
+		"custom layout": {timestampFormat: "02 Jan 2006 15:04:05 MST", expected: "02 Jan 2030 09:30:15 UTC"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			api := &testOrderAPI{orders: map[string]Order{}}
+			settings := providerSettings{timestampFormat: test.timestampFormat, now: func() time.Time { return now }}
+			o := &orderResource{client: newTestOrderClient(t, api), settings: settings}
+			s := testResourceSchema(t, o)
+
+			createResp := &fwresource.CreateResponse{State: testState(t, s, nil)}
+			o.Create(ctx, fwresource.CreateRequest{Plan: testPlan(t, s, &orderResourceModel{
+				ID:          types.StringUnknown(),
+				Items:       []orderItemModel{testUnknownOrderItem(1, 1)},
+				FromCoffees: types.ListNull(types.Int64Type),
+				Timeouts:    testOrderTimeouts(nil),
+				LastUpdated: types.StringUnknown(),
+			})}, createResp)
+			if createResp.Diagnostics.HasError() {
+				t.Fatalf("create: %v", createResp.Diagnostics)
+			}
+
+			var created orderResourceModel
+			createResp.State.Get(ctx, &created)
+			if got := created.LastUpdated.ValueString(); got != test.expected {
+				t.Fatalf("expected last_updated %q, got %q", test.expected, got)
+			}
+
+			// A timestamp written before the format changed is rewritten on
+			// read, and one in the current format is kept.
+			for _, prior := range []string{now.Format(time.RFC850), test.expected} {
+				created.LastUpdated = types.StringValue(prior)
+				state := testState(t, s, &created)
+				readResp := &fwresource.ReadResponse{State: state}
+				o.Read(ctx, fwresource.ReadRequest{State: state}, readResp)
+				if readResp.Diagnostics.HasError() {
+					t.Fatalf("read: %v", readResp.Diagnostics)
+				}
+
+				var read orderResourceModel
+				readResp.State.Get(ctx, &read)
+				if got := read.LastUpdated.ValueString(); got != test.expected {
+					t.Errorf("expected %q to be read back as %q, got %q", prior, test.expected, got)
+				}
+			}
+		})
+	}
+}
+
+func TestOrderResourceValidateReadOnlyItemAttributes(t *testing.T) {
+	ctx := context.Background()
+
+	withPrice := testUnknownOrderItem(1, 1)
+	withPrice.Coffee.Price = types.Float64Value(200)
+	withLineTotal := testUnknownOrderItem(2, 1)
+	withLineTotal.LineTotal = types.Float64Value(150)
+	// The name is configurable, as an alternative to the ID.
+	withName := testUnknownOrderItem(0, 1)
+	withName.Coffee.ID = types.Int64Null()
+	withName.Coffee.Name = types.StringValue("HCP Aeropress")
+
+	tests := map[string]struct {
+		items       []orderItemModel
+		expectPaths []path.Path
+	}{
+		"configurable attributes": {
+			items: []orderItemModel{testUnknownOrderItem(1, 1), withName},
+		},
+		"coffee price": {
+			items:       []orderItemModel{testUnknownOrderItem(2, 1), withPrice},
+			expectPaths: []path.Path{path.Root("items").AtListIndex(1).AtName("coffee").AtName("price")},
+		},
+		"line total": {
+			items:       []orderItemModel{withLineTotal},
+			expectPaths: []path.Path{path.Root("items").AtListIndex(0).AtName("line_total")},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			o := &orderResource{}
+			s := testResourceSchema(t, o)
+
+			resp := &fwresource.ValidateConfigResponse{}
+			o.ValidateConfig(ctx, fwresource.ValidateConfigRequest{
+				Config: tfsdk.Config{Schema: s, Raw: testResourceValue(t, s, map[string]any{"items": test.items})},
+			}, resp)
+
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != len(test.expectPaths) {
+				t.Fatalf("expected %d errors, got %v", len(test.expectPaths), resp.Diagnostics)
+			}
+			for i, expected := range test.expectPaths {
+				withPath, ok := errs[i].(diag.DiagnosticWithPath)
+				if !ok || !withPath.Path().Equal(expected) {
+					t.Errorf("expected an error at %s, got %v", expected, errs[i])
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,74 @@
+package hashicups
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &orderTotalFunction{}
+
+// orderTotalItemAttrTypes is the item shape accepted by order_total.
+var orderTotalItemAttrTypes = map[string]attr.Type{
+	"price":    types.Float64Type,
+	"quantity": types.Int64Type,
+}
+
+func NewOrderTotalFunction() function.Function {
+	return &orderTotalFunction{}
+}
+
+type orderTotalFunction struct{}
+
+// orderTotalItemModel maps an item of the function argument.
+type orderTotalItemModel struct {
+	Price    types.Float64 `tfsdk:"price"`
+	Quantity types.Int64   `tfsdk:"quantity"`
+}
+
+func (f *orderTotalFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "order_total"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *orderTotalFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary:     "Compute the total of an order.",
+		Description: "Returns the sum of price multiplied by quantity over a list of price and quantity objects, without reading the HashiCups API.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "items",
+				Description: "Order items, a list of objects with price and a non-negative quantity.",
+				ElementType: types.ObjectType{AttrTypes: orderTotalItemAttrTypes},
+			},
+		},
+		Return: function.Float64Return{},
+	}
+}
+
+// Run sums the cost of each item.
+func (f *orderTotalFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var items []orderTotalItemModel
+
+	response.Error = request.Arguments.Get(ctx, &items)
+	if response.Error != nil {
+		return
+	}
+
+	var total float64
+	for i, item := range items {
+		if item.Quantity.ValueInt64() < 0 {
+			response.Error = function.NewArgumentFuncError(0,
+				fmt.Sprintf("Item %d: quantity %d must not be negative.", i, item.Quantity.ValueInt64()))
+			return
+		}
+
+		total += item.Price.ValueFloat64() * float64(item.Quantity.ValueInt64())
+	}
+
+	response.Error = response.Result.Set(ctx, total)
+}
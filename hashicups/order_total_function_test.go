@@ -0,0 +1,55 @@
+package hashicups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestOrderTotalFunction(t *testing.T) {
+	tests := map[string]struct {
+		items       []orderTotalItemModel
+		expected    float64
+		expectError bool
+	}{
+		"no items": {
+			items: []orderTotalItemModel{},
+		},
+		"several items": {
+			items: []orderTotalItemModel{
+				{Price: types.Float64Value(200), Quantity: types.Int64Value(2)},
+				{Price: types.Float64Value(150.5), Quantity: types.Int64Value(1)},
+				{Price: types.Float64Value(99), Quantity: types.Int64Value(0)},
+			},
+			expected: 550.5,
+		},
+		"negative quantity": {
+			items: []orderTotalItemModel{
+				{Price: types.Float64Value(200), Quantity: types.Int64Value(1)},
+				{Price: types.Float64Value(150.5), Quantity: types.Int64Value(-1)},
+			},
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			items, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: orderTotalItemAttrTypes}, test.items)
+			if diags.HasError() {
+				t.Fatalf("unable to build items: %v", diags)
+			}
+
+			resp := runTestFunction(t, NewOrderTotalFunction(), items)
+			if (resp.Error != nil) != test.expectError {
+				t.Fatalf("expected error %t, got %v", test.expectError, resp.Error)
+			}
+			if test.expectError {
+				return
+			}
+			if got := resp.Result.Value().(types.Float64).ValueFloat64(); got != test.expected {
+				t.Errorf("expected %v, got %v", test.expected, got)
+			}
+		})
+	}
+}
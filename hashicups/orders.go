@@ -1,27 +1,48 @@
 package hashicups
 
 import (
-	"encoding/json"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
 )
 
+// ErrInvalidOrder is returned without contacting the API when an order
+// payload is obviously invalid.
+var ErrInvalidOrder = errors.New("invalid order")
+
+// validateOrderItems checks an order payload before it is sent.
+func validateOrderItems(orderItems []OrderItem) error {
+	if len(orderItems) == 0 {
+		return fmt.Errorf("%w: an order must contain at least one item", ErrInvalidOrder)
+	}
+
+	for i, item := range orderItems {
+		if item.Quantity < 0 {
+			return fmt.Errorf("%w: item %d has negative quantity %d", ErrInvalidOrder, i, item.Quantity)
+		}
+	}
+
+	return nil
+}
+
 // GetOrder - Returns a specifc order
-func (c *Client) GetOrder(orderID string) (*Order, error) {
-	req, err := http.NewRequest("GET", fmt.Sprintf("%s/orders/%s", c.HostURL, orderID), nil)
+func (c *Client) GetOrder(ctx context.Context, orderID string) (*Order, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/orders/%s", c.baseURL(), orderID), nil)
 	if err != nil {
 		return nil, err
 	}
 
-	body, err := c.doRequest(req)
+	body, err := c.doRequestWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
 	order := Order{}
-	err = json.Unmarshal(body, &order)
+	err = c.decode(body, &order)
 	if err != nil {
 		return nil, err
 	}
@@ -29,14 +50,104 @@ func (c *Client) GetOrder(orderID string) (*Order, error) {
 	return &order, nil
 }
 
+// GetOrders - Returns all orders
+func (c *Client) GetOrders(ctx context.Context) ([]Order, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/orders", c.baseURL()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []Order{}
+	err = c.decode(body, &orders)
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
 // CreateOrder - Create new order
-func (c *Client) CreateOrder(orderItems []OrderItem) (*Order, error) {
-	rb, err := json.Marshal(orderItems)
+func (c *Client) CreateOrder(ctx context.Context, orderItems []OrderItem) (*Order, error) {
+	return c.createOrder(ctx, fmt.Sprintf("%s/orders", c.baseURL()), orderItems)
+}
+
+// ScheduleOrder - Create new order to be placed at a future time
+func (c *Client) ScheduleOrder(ctx context.Context, orderItems []OrderItem, scheduledFor time.Time) (*Order, error) {
+	return c.CreateOrderWithOptions(ctx, orderItems, OrderOptions{ScheduledFor: &scheduledFor})
+}
+
+// OrderOptions - Optional settings of a new order
+type OrderOptions struct {
+	// ScheduledFor places the order at a future time when set.
+	ScheduledFor *time.Time
+	// ExternalID is a caller-chosen key identifying the order.
+	ExternalID string
+}
+
+// CreateOrderWithOptions - Create new order with optional settings
+func (c *Client) CreateOrderWithOptions(ctx context.Context, orderItems []OrderItem, options OrderOptions) (*Order, error) {
+	query := url.Values{}
+	if options.ScheduledFor != nil {
+		query.Set("scheduled_for", options.ScheduledFor.Format(time.RFC3339))
+	}
+	if options.ExternalID != "" {
+		query.Set("external_id", options.ExternalID)
+	}
+
+	ordersURL := fmt.Sprintf("%s/orders", c.baseURL())
+	if len(query) > 0 {
+		ordersURL += "?" + query.Encode()
+	}
+
+	return c.createOrder(ctx, ordersURL, orderItems)
+}
+
+// FindOrderByExternalID - Returns the order with the external ID, or nil when
+// there is none
+func (c *Client) FindOrderByExternalID(ctx context.Context, externalID string) (*Order, error) {
+	query := url.Values{"external_id": {externalID}}
+	req, err := http.NewRequestWithContext(ctx, "GET", fmt.Sprintf("%s/orders?%s", c.baseURL(), query.Encode()), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := c.doRequestWithRetry(ctx, req)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", fmt.Sprintf("%s/orders", c.HostURL), strings.NewReader(string(rb)))
+	orders := []Order{}
+	err = c.decode(body, &orders)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, order := range orders {
+		if order.ExternalID == externalID {
+			return &order, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (c *Client) createOrder(ctx context.Context, ordersURL string, orderItems []OrderItem) (*Order, error) {
+	err := validateOrderItems(orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	rb, err := c.encode(orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", ordersURL, strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -47,7 +158,7 @@ func (c *Client) CreateOrder(orderItems []OrderItem) (*Order, error) {
 	}
 
 	order := Order{}
-	err = json.Unmarshal(body, &order)
+	err = c.decode(body, &order)
 	if err != nil {
 		return nil, err
 	}
@@ -56,13 +167,18 @@ func (c *Client) CreateOrder(orderItems []OrderItem) (*Order, error) {
 }
 
 // UpdateOrder - Updates an order
-func (c *Client) UpdateOrder(orderID string, orderItems []OrderItem) (*Order, error) {
-	rb, err := json.Marshal(orderItems)
+func (c *Client) UpdateOrder(ctx context.Context, orderID string, orderItems []OrderItem) (*Order, error) {
+	err := validateOrderItems(orderItems)
 	if err != nil {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/orders/%s", c.HostURL, orderID), strings.NewReader(string(rb)))
+	rb, err := c.encode(orderItems)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "PUT", fmt.Sprintf("%s/orders/%s", c.baseURL(), orderID), strings.NewReader(string(rb)))
 	if err != nil {
 		return nil, err
 	}
@@ -73,7 +189,7 @@ func (c *Client) UpdateOrder(orderID string, orderItems []OrderItem) (*Order, er
 	}
 
 	order := Order{}
-	err = json.Unmarshal(body, &order)
+	err = c.decode(body, &order)
 	if err != nil {
 		return nil, err
 	}
@@ -81,19 +197,26 @@ func (c *Client) UpdateOrder(orderID string, orderItems []OrderItem) (*Order, er
 	return &order, nil
 }
 
-// DeleteOrder - Deletes an order
-func (c *Client) DeleteOrder(orderID string) error {
-	req, err := http.NewRequest("DELETE", fmt.Sprintf("%s/orders/%s", c.HostURL, orderID), nil)
+// DeleteOrder - Deletes an order, retrying transient failures
+func (c *Client) DeleteOrder(ctx context.Context, orderID string) error {
+	req, err := http.NewRequestWithContext(ctx, "DELETE", fmt.Sprintf("%s/orders/%s", c.baseURL(), orderID), nil)
 	if err != nil {
 		return err
 	}
 
-	body, err := c.doRequest(req)
+	body, err := c.doRequestWithRetry(ctx, req)
+	var retryErr *retryError
+	var apiErr *APIError
+	if errors.As(err, &retryErr) && errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		// An earlier attempt deleted the order but its response was lost.
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	if string(body) != "Deleted order" {
+	// Some servers confirm with an empty body or 204 No Content.
+	if len(body) > 0 && string(body) != "Deleted order" {
 		return errors.New(string(body))
 	}
 
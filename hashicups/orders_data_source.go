@@ -0,0 +1,124 @@
+package hashicups
+
+import (
+	"context"
+	"strconv"
+
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &ordersDataSource{}
+	_ datasource.DataSourceWithConfigure = &ordersDataSource{}
+)
+
+func NewOrdersDataSource() datasource.DataSource {
+	return &ordersDataSource{}
+}
+
+// ordersDataSource lists the existing orders, for example for reporting.
+type ordersDataSource struct {
+	client   *Client
+	settings providerSettings
+}
+
+// ordersDataSourceModel maps the data source schema data.
+type ordersDataSourceModel struct {
+	Limit  types.Int64        `tfsdk:"limit"`
+	Orders []ordersOrderModel `tfsdk:"orders"`
+}
+
+// ordersOrderModel maps an order of the orders data source.
+type ordersOrderModel struct {
+	ID    types.String     `tfsdk:"id"`
+	Items []orderItemModel `tfsdk:"items"`
+}
+
+func (d *ordersDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_orders"
+}
+
+// Schema defines the schema for the data source.
+func (d *ordersDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Fetches the list of existing orders.",
+		Attributes: map[string]schema.Attribute{
+			"limit": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Return at most this many orders, the first ones listed by the API.",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"orders": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "List of orders.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"id": schema.StringAttribute{
+							Computed:    true,
+							Description: "Numeric identifier of the order.",
+						},
+						"items": orderItemsDataSourceAttribute(),
+					},
+				},
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest data.
+func (d *ordersDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	defer addDeprecationWarnings(&resp.Diagnostics, d.client)
+
+	var state ordersDataSourceModel
+	diags := req.Config.Get(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	orders, err := d.client.GetOrders(ctx)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Unable to Read HashiCups Orders",
+			"Could not list HashiCups orders: "+err.Error(),
+		)
+		return
+	}
+
+	if !state.Limit.IsNull() && int64(len(orders)) > state.Limit.ValueInt64() {
+		orders = orders[:state.Limit.ValueInt64()]
+	}
+
+	state.Orders = nil
+	for _, order := range orders {
+		model := ordersOrderModel{
+			ID:    types.StringValue(strconv.Itoa(order.ID)),
+			Items: []orderItemModel{},
+		}
+		for _, item := range sortOrderItems(order.Items, d.settings.orderItemSort) {
+			model.Items = append(model.Items, newOrderItemModel(item))
+		}
+		state.Orders = append(state.Orders, model)
+	}
+	state.Orders = listOrNull(state.Orders, d.settings.emptyListsAsNull)
+
+	diags = resp.State.Set(ctx, &state)
+	resp.Diagnostics.Append(diags...)
+}
+
+func (d *ordersDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	data := request.ProviderData.(*providerData)
+	d.client = data.client
+	d.settings = data.settings
+}
@@ -0,0 +1,75 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestOrdersDataSourceRead(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/orders" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(`[
+			{"id":1,"items":[{"coffee":{"id":1,"name":"HCP Aeropress","price":200},"quantity":2,"note":"extra hot"}]},
+			{"id":2,"items":null},
+			{"id":3,"items":[{"coffee":{"id":2,"name":"Vaulatte","price":150},"quantity":1}]}
+		]`))
+	})
+	d := &ordersDataSource{client: client}
+
+	resp := readTestDataSource(t, d, &ordersDataSourceModel{Limit: types.Int64Null()})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	var state ordersDataSourceModel
+	resp.State.Get(context.Background(), &state)
+	if len(state.Orders) != 3 {
+		t.Fatalf("expected 3 orders, got %+v", state.Orders)
+	}
+	if order := state.Orders[0]; order.ID.ValueString() != "1" || len(order.Items) != 1 ||
+		order.Items[0].Coffee.Name.ValueString() != "HCP Aeropress" || order.Items[0].LineTotal.ValueFloat64() != 400 ||
+		order.Items[0].Note.ValueString() != "extra hot" {
+		t.Errorf("unexpected first order: %+v", order)
+	}
+	if order := state.Orders[1]; order.Items == nil || len(order.Items) != 0 {
+		t.Errorf("expected empty items for order 2, got %+v", order.Items)
+	}
+
+	resp = readTestDataSource(t, d, &ordersDataSourceModel{Limit: types.Int64Value(2)})
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+	resp.State.Get(context.Background(), &state)
+	if len(state.Orders) != 2 || state.Orders[1].ID.ValueString() != "2" {
+		t.Errorf("expected the first 2 orders, got %+v", state.Orders)
+	}
+}
+
+func TestOrdersDataSourceReadEmpty(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	for _, asNull := range []bool{false, true} {
+		d := &ordersDataSource{client: client, settings: providerSettings{emptyListsAsNull: asNull}}
+		resp := readTestDataSource(t, d, &ordersDataSourceModel{Limit: types.Int64Null()})
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+
+		var state ordersDataSourceModel
+		resp.State.Get(context.Background(), &state)
+		if asNull && state.Orders != nil {
+			t.Errorf("expected null orders, got %+v", state.Orders)
+		}
+		if !asNull && (state.Orders == nil || len(state.Orders) != 0) {
+			t.Errorf("expected empty orders, got %+v", state.Orders)
+		}
+	}
+}
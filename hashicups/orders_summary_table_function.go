@@ -0,0 +1,105 @@
+package hashicups
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var _ function.Function = &ordersSummaryTableFunction{}
+
+// ordersSummaryTableOrderAttrTypes is the order shape accepted by
+// orders_summary_table. Both the hashicups_order resource and data source
+// provide these attributes.
+var ordersSummaryTableOrderAttrTypes = map[string]attr.Type{
+	"id":          types.StringType,
+	"item_count":  types.Int64Type,
+	"total_price": types.Float64Type,
+}
+
+func NewOrdersSummaryTableFunction() function.Function {
+	return &ordersSummaryTableFunction{}
+}
+
+type ordersSummaryTableFunction struct{}
+
+// ordersSummaryTableOrderModel maps an order of the function argument.
+type ordersSummaryTableOrderModel struct {
+	ID         types.String  `tfsdk:"id"`
+	ItemCount  types.Int64   `tfsdk:"item_count"`
+	TotalPrice types.Float64 `tfsdk:"total_price"`
+}
+
+func (f *ordersSummaryTableFunction) Metadata(_ context.Context, _ function.MetadataRequest, response *function.MetadataResponse) {
+	response.Name = "orders_summary_table"
+}
+
+// Definition defines the parameters and return type of the function.
+func (f *ordersSummaryTableFunction) Definition(_ context.Context, _ function.DefinitionRequest, response *function.DefinitionResponse) {
+	response.Definition = function.Definition{
+		Summary: "Format orders as a text table.",
+		Description: "Returns a table with the ID, item count, and total price of each order, one order per line, " +
+			"for use in outputs. Numbers are right-aligned and missing values are shown as -.",
+		Parameters: []function.Parameter{
+			function.ListParameter{
+				Name:        "orders",
+				Description: "Orders, a list of objects with id, item_count, and total_price.",
+				ElementType: types.ObjectType{AttrTypes: ordersSummaryTableOrderAttrTypes},
+			},
+		},
+		Return: function.StringReturn{},
+	}
+}
+
+// Run formats the orders.
+func (f *ordersSummaryTableFunction) Run(ctx context.Context, request function.RunRequest, response *function.RunResponse) {
+	var orders []ordersSummaryTableOrderModel
+
+	response.Error = request.Arguments.Get(ctx, &orders)
+	if response.Error != nil {
+		return
+	}
+
+	response.Error = response.Result.Set(ctx, ordersSummaryTable(orders))
+}
+
+// ordersSummaryTable formats orders with a header row. The ID column is
+// left-aligned and the numeric columns are right-aligned.
+func ordersSummaryTable(orders []ordersSummaryTableOrderModel) string {
+	rows := [][]string{
+		{"ID", "ITEMS", "TOTAL"},
+		{"--", "-----", "-----"},
+	}
+	for _, order := range orders {
+		row := []string{"-", "-", "-"}
+		if !order.ID.IsNull() && !order.ID.IsUnknown() {
+			row[0] = order.ID.ValueString()
+		}
+		if !order.ItemCount.IsNull() && !order.ItemCount.IsUnknown() {
+			row[1] = fmt.Sprint(order.ItemCount.ValueInt64())
+		}
+		if !order.TotalPrice.IsNull() && !order.TotalPrice.IsUnknown() {
+			row[2] = fmt.Sprintf("%.2f", order.TotalPrice.ValueFloat64())
+		}
+		rows = append(rows, row)
+	}
+
+	widths := make([]int, len(rows[0]))
+	for _, row := range rows {
+		for i, cell := range row {
+			widths[i] = max(widths[i], len(cell))
+		}
+	}
+
+	var table strings.Builder
+	for _, row := range rows {
+		fmt.Fprintf(&table, "%-*s  %*s  %*s\n", widths[0], row[0], widths[1], row[1], widths[2], row[2])
+	}
+
+	return table.String()
+}
@@ -0,0 +1,60 @@
+package hashicups
+
+import (
+	"context"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestOrdersSummaryTableFunction(t *testing.T) {
+	tests := map[string]struct {
+		orders   []ordersSummaryTableOrderModel
+		expected string
+	}{
+		"zero orders": {
+			expected: "" +
+				"ID  ITEMS  TOTAL\n" +
+				"--  -----  -----\n",
+		},
+		"one order": {
+			orders: []ordersSummaryTableOrderModel{
+				{ID: types.StringValue("1"), ItemCount: types.Int64Value(3), TotalPrice: types.Float64Value(24.5)},
+			},
+			expected: "" +
+				"ID  ITEMS  TOTAL\n" +
+				"--  -----  -----\n" +
+				"1       3  24.50\n",
+		},
+		"several orders": {
+			orders: []ordersSummaryTableOrderModel{
+				{ID: types.StringValue("7"), ItemCount: types.Int64Value(1), TotalPrice: types.Float64Value(2)},
+				{ID: types.StringValue("order-1024"), ItemCount: types.Int64Value(120), TotalPrice: types.Float64Value(1234.567)},
+				{ID: types.StringValue("42"), ItemCount: types.Int64Null(), TotalPrice: types.Float64Unknown()},
+			},
+			expected: "" +
+				"ID          ITEMS    TOTAL\n" +
+				"--          -----    -----\n" +
+				"7               1     2.00\n" +
+				"order-1024    120  1234.57\n" +
+				"42              -        -\n",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			orders, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: ordersSummaryTableOrderAttrTypes}, test.orders)
+			if diags.HasError() {
+				t.Fatalf("unable to build orders: %v", diags)
+			}
+
+			resp := runTestFunction(t, NewOrdersSummaryTableFunction(), orders)
+			if resp.Error != nil {
+				t.Fatalf("unexpected error: %s", resp.Error)
+			}
+			if got := resp.Result.Value().(types.String).ValueString(); got != test.expected {
+				t.Errorf("expected\n%s\ngot\n%s", test.expected, got)
+			}
+		})
+	}
+}
@@ -2,36 +2,310 @@ package hashicups
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/hashicorp/terraform-plugin-framework-validators/float64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"golang.org/x/text/language"
 
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 )
 
 var (
-	_ provider.Provider = &hashicupsProvider{}
+	_ provider.Provider                   = &hashicupsProvider{}
+	_ provider.ProviderWithFunctions      = &hashicupsProvider{}
+	_ provider.ProviderWithValidateConfig = &hashicupsProvider{}
 )
 
-func New() provider.Provider {
-	return &hashicupsProvider{}
+func New(version, commit string) func() provider.Provider {
+	return func() provider.Provider {
+		return &hashicupsProvider{
+			version: version,
+			commit:  commit,
+		}
+	}
+}
+
+type hashicupsProvider struct {
+	// version is set to the provider version on release, "dev" when the
+	// provider is built and ran locally, and "test" when running acceptance
+	// testing.
+	version string
+	// commit is the git commit the provider was built from.
+	commit string
+}
+
+// providerData is made available to data sources and resources during their
+// Configure methods.
+type providerData struct {
+	client   *Client
+	settings providerSettings
+	catalog  *coffeeCatalog
 }
 
-type hashicupsProvider struct{}
+// coffeeCatalog caches the coffee catalog so that configuration validation
+// and order reads fetch it once, however many orders reference it. A
+// successful read is kept for the life of the provider, one Terraform run, and
+// never refreshed. Failed reads are not cached, so a later caller reads again.
+type coffeeCatalog struct {
+	client *Client
+
+	mu      sync.Mutex
+	loaded  bool
+	ids     map[int64]bool
+	coffees map[int64]Coffee
+}
+
+// load reads the catalog unless an earlier read succeeded.
+func (c *coffeeCatalog) load(ctx context.Context) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.loaded {
+		return nil
+	}
+
+	coffees, err := c.client.GetCoffees(ctx)
+	if err != nil {
+		return err
+	}
+
+	c.ids = make(map[int64]bool, len(coffees))
+	c.coffees = make(map[int64]Coffee, len(coffees))
+	for _, coffee := range coffees {
+		c.ids[int64(coffee.ID)] = true
+		c.coffees[int64(coffee.ID)] = coffee
+	}
+	c.loaded = true
+
+	return nil
+}
+
+// coffeeIDs returns the set of coffee IDs in the catalog.
+func (c *coffeeCatalog) coffeeIDs(ctx context.Context) (map[int64]bool, error) {
+	if err := c.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.ids, nil
+}
+
+// coffeesByID returns the coffees in the catalog keyed by ID.
+func (c *coffeeCatalog) coffeesByID(ctx context.Context) (map[int64]Coffee, error) {
+	if err := c.load(ctx); err != nil {
+		return nil, err
+	}
+
+	return c.coffees, nil
+}
+
+// providerSettings holds provider configuration that affects data source and
+// resource behaviour rather than the API client.
+type providerSettings struct {
+	// readOnly refuses all resource write operations.
+	readOnly bool
+	// checkStock validates planned order quantities against coffee stock.
+	checkStock bool
+	// emptyListsAsNull maps empty API lists to null rather than empty lists.
+	emptyListsAsNull bool
+	// orderItemSort is the order_item_sort mode applied to order items read
+	// from the API.
+	orderItemSort string
+	// prepTimeMode is the prep_time_mode used to combine the preparation
+	// times of order items.
+	prepTimeMode string
+	// validateCoffeeIDs checks the coffee IDs of order configurations
+	// against the catalog.
+	validateCoffeeIDs bool
+	// defaultOrderTimeout bounds order operations whose timeouts block does
+	// not set a duration. Zero means defaultOrderOperationTimeout.
+	defaultOrderTimeout time.Duration
+	// createBatchSize is the most items sent in one request when creating
+	// an order. Zero means all items are sent at once.
+	createBatchSize int
+	// orderingHours limits order creates and updates to a daily window. Nil
+	// means orders are accepted at any time.
+	orderingHours *orderingWindow
+	// timestampFormat is the time layout of timestamps written to state,
+	// such as the order last_updated. Empty means RFC3339.
+	timestampFormat string
+	// newWindow is how long after its creation a coffee is reported as new.
+	newWindow time.Duration
+	// promoPercent is the discount applied to coffee discount prices.
+	promoPercent float64
+	// priceRounding is the price_rounding mode of discount prices.
+	priceRounding string
+	// now returns the current time. It is nil outside of tests.
+	now func() time.Time
+}
+
+// currentTime returns the current time from the injected clock, if any.
+func (s providerSettings) currentTime() time.Time {
+	if s.now != nil {
+		return s.now()
+	}
+
+	return time.Now()
+}
+
+// formatTimestamp formats t in the timestamp_format.
+func (s providerSettings) formatTimestamp(t time.Time) string {
+	if s.timestampFormat == "" {
+		return t.Format(time.RFC3339)
+	}
+
+	return t.Format(s.timestampFormat)
+}
+
+// reformatTimestamp rewrites a timestamp written in another standard layout,
+// such as before timestamp_format was changed, in the timestamp_format.
+// Values that parse in the timestamp_format or in no known layout are kept.
+func (s providerSettings) reformatTimestamp(value types.String) types.String {
+	if value.IsNull() || value.IsUnknown() {
+		return value
+	}
+
+	layout := s.timestampFormat
+	if layout == "" {
+		layout = time.RFC3339
+	}
+	if _, err := time.Parse(layout, value.ValueString()); err == nil {
+		return value
+	}
+
+	for _, known := range timestampLayouts {
+		if t, err := time.Parse(known, value.ValueString()); err == nil {
+			return types.StringValue(t.Format(layout))
+		}
+	}
+
+	return value
+}
+
+// discountPrice returns price less the promo_percent discount, rounded to
+// cents in the price_rounding mode.
+func (s providerSettings) discountPrice(price float64) float64 {
+	cents := price * (100 - s.promoPercent)
+
+	switch s.priceRounding {
+	case priceRoundingHalfEven:
+		cents = math.RoundToEven(cents)
+	case priceRoundingDown:
+		// Allow for products such as 1.15 * 100 landing just below a whole
+		// number of cents.
+		cents = math.Floor(cents + 1e-9)
+	default:
+		cents = math.Round(cents)
+	}
+
+	return cents / 100
+}
+
+// timestampLayouts are the standard layouts timestamp_format accepts by name.
+var timestampLayouts = map[string]string{
+	"RFC3339":     time.RFC3339,
+	"RFC3339Nano": time.RFC3339Nano,
+	"RFC1123":     time.RFC1123,
+	"RFC1123Z":    time.RFC1123Z,
+	"RFC822":      time.RFC822,
+	"RFC822Z":     time.RFC822Z,
+	"RFC850":      time.RFC850,
+	"DateTime":    time.DateTime,
+}
+
+// parseTimestampFormat returns the layout of a timestamp_format, either the
+// name of a standard layout or a Go layout. A layout must keep the date and
+// time to the second, so timestamps read back from state are unambiguous.
+func parseTimestampFormat(format string) (string, error) {
+	if layout, ok := timestampLayouts[format]; ok {
+		return layout, nil
+	}
+
+	reference := time.Date(2006, time.January, 2, 15, 4, 5, 0, time.UTC)
+	parsed, err := time.Parse(format, reference.Format(format))
+	if err != nil {
+		return "", err
+	}
+	if !parsed.Equal(reference) {
+		return "", fmt.Errorf("the layout does not keep the full date and time, %s formats as %q", reference.Format(time.RFC3339), reference.Format(format))
+	}
+
+	return format, nil
+}
 
 type hashicupsProviderModel struct {
 	Host     types.String `tfsdk:"host"`
 	Username types.String `tfsdk:"username"`
 	Password types.String `tfsdk:"password"`
+
+	DisallowUnknownFields types.Bool    `tfsdk:"disallow_unknown_fields"`
+	ReadOnly              types.Bool    `tfsdk:"read_only"`
+	RateLimit             types.Float64 `tfsdk:"rate_limit"`
+	CheckStock            types.Bool    `tfsdk:"check_stock"`
+	AcceptStatus          types.List    `tfsdk:"accept_status"`
+	AuthScheme            types.String  `tfsdk:"auth_scheme"`
+	EmptyListsAsNull      types.Bool    `tfsdk:"empty_lists_as_null"`
+	MaxConcurrentRequests types.Int64   `tfsdk:"max_concurrent_requests"`
+	CompressRequests      types.Bool    `tfsdk:"compress_requests"`
+	DeduplicateRequests   types.Bool    `tfsdk:"deduplicate_requests"`
+	Token                 types.String  `tfsdk:"token"`
+	APIKey                types.String  `tfsdk:"api_key"`
+	APIKeyHeader          types.String  `tfsdk:"api_key_header"`
+	CACertFile            types.String  `tfsdk:"ca_cert_file"`
+	CACertPEM             types.String  `tfsdk:"ca_cert_pem"`
+	InsecureSkipVerify    types.Bool    `tfsdk:"insecure_skip_verify"`
+	ProxyURL              types.String  `tfsdk:"proxy_url"`
+	APIPath               types.String  `tfsdk:"api_path"`
+	UserAgentSuffix       types.String  `tfsdk:"user_agent_suffix"`
+	MaxRetries            types.Int64   `tfsdk:"max_retries"`
+	RetryWaitMin          types.String  `tfsdk:"retry_wait_min"`
+	RetryWaitMax          types.String  `tfsdk:"retry_wait_max"`
+	RetryMaxElapsedTime   types.String  `tfsdk:"retry_max_elapsed_time"`
+	AcceptLanguage        types.String  `tfsdk:"accept_language"`
+	OrderItemSort         types.String  `tfsdk:"order_item_sort"`
+	PrepTimeMode          types.String  `tfsdk:"prep_time_mode"`
+	DefaultOrderTimeout   types.String  `tfsdk:"default_order_timeout"`
+	CreateBatchSize       types.Int64   `tfsdk:"create_batch_size"`
+	ValidateCoffeeIDs     types.Bool    `tfsdk:"validate_coffee_ids"`
+	OrderingHours         types.String  `tfsdk:"ordering_hours"`
+	OrderingTimezone      types.String  `tfsdk:"ordering_timezone"`
+	NewWindow             types.String  `tfsdk:"new_window"`
+	TimestampFormat       types.String  `tfsdk:"timestamp_format"`
+	PromoPercent          types.Float64 `tfsdk:"promo_percent"`
+	PriceRounding         types.String  `tfsdk:"price_rounding"`
 }
 
 func (p *hashicupsProvider) Metadata(_ context.Context, _ provider.MetadataRequest, resp *provider.MetadataResponse) {
 	resp.TypeName = "hashicups"
+	resp.Version = p.version
+}
+
+// buildInfo describes the provider build for inclusion in diagnostics.
+func (p *hashicupsProvider) buildInfo() string {
+	return fmt.Sprintf("Provider version: %s, commit: %s", p.version, p.commit)
 }
 
 // Schema defines the provider-level schema for configuration data.
@@ -51,12 +325,248 @@ func (p *hashicupsProvider) Schema(_ context.Context, _ provider.SchemaRequest,
 				Optional:    true,
 				Sensitive:   true,
 			},
+			"auth_scheme": schema.StringAttribute{
+				Description: "How requests are authenticated: `token` (default) signs in and sends the returned token, " +
+					"`bearer` signs in and sends the token with the Bearer scheme, `basic` sends the username and password " +
+					"with HTTP basic authentication, and `hmac` signs each request using the username as key ID and the password as secret.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(authSchemeToken, authSchemeBearer, authSchemeBasic, authSchemeHMAC),
+				},
+			},
+			"token": schema.StringAttribute{
+				Description: "Static API token sent as a bearer token with each request instead of signing in. Takes precedence over username and password, which are not required. Conflicts with api_key and auth_scheme.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"api_key": schema.StringAttribute{
+				Description: "API key sent with each request instead of signing in. Conflicts with auth_scheme; username and password are not required.",
+				Optional:    true,
+				Sensitive:   true,
+			},
+			"api_key_header": schema.StringAttribute{
+				Description: "Header the api_key is sent under. Defaults to `" + defaultAPIKeyHeader + "`.",
+				Optional:    true,
+			},
+			"ca_cert_file": schema.StringAttribute{
+				Description: "Path to a PEM file of certificate authorities trusted when verifying the HashiCups server, " +
+					"for servers behind a TLS proxy with a private CA. Conflicts with ca_cert_pem.",
+				Optional: true,
+			},
+			"ca_cert_pem": schema.StringAttribute{
+				Description: "PEM-encoded certificate authorities trusted when verifying the HashiCups server. Conflicts with ca_cert_file.",
+				Optional:    true,
+			},
+			"insecure_skip_verify": schema.BoolAttribute{
+				Description: "Skip verification of the HashiCups server certificate, for development servers with self-signed certificates. " +
+					"Never enable this in production. Defaults to false.",
+				Optional: true,
+			},
+			"api_path": schema.StringAttribute{
+				Description: "Path prefix of the HashiCups API on the host, such as `/hashicups/v1` for a server routed under that path. Defaults to the host root.",
+				Optional:    true,
+			},
+			"user_agent_suffix": schema.StringAttribute{
+				Description: "Text appended to the User-Agent header of every HashiCups API request, such as `ci-pipeline/1.2`, " +
+					"after the default `terraform-provider-hashicups/<version> (terraform-plugin-framework)`.",
+				Optional: true,
+			},
+			"proxy_url": schema.StringAttribute{
+				Description: "URL of an HTTP, HTTPS, or SOCKS5 proxy all HashiCups API requests are sent through, such as `http://proxy.example.com:3128`. " +
+					"Defaults to the proxy set by the HTTP_PROXY, HTTPS_PROXY, and NO_PROXY environment variables.",
+				Optional: true,
+			},
+			"disallow_unknown_fields": schema.BoolAttribute{
+				Description: "Reject HashiCups API responses containing unexpected fields. Useful for contract testing against a known server version. Defaults to false.",
+				Optional:    true,
+			},
+			"read_only": schema.BoolAttribute{
+				Description: "Refuse all resource create, update, and delete operations while leaving data sources functional. Defaults to false.",
+				Optional:    true,
+			},
+			"rate_limit": schema.Float64Attribute{
+				Description: "Maximum requests per second sent to each HashiCups API host. Defaults to no limit.",
+				Optional:    true,
+			},
+			"check_stock": schema.BoolAttribute{
+				Description: "Check planned order quantities against the current coffee stock, where the API reports it. Defaults to false.",
+				Optional:    true,
+			},
+			"accept_status": schema.ListAttribute{
+				ElementType: types.Int64Type,
+				Description: "Additional HTTP status codes treated as success for nonstandard servers. Any 2xx status is always a success.",
+				Optional:    true,
+			},
+			"max_concurrent_requests": schema.Int64Attribute{
+				Description: "Maximum HashiCups API requests in flight at once across all operations. Further requests wait for a free slot. Defaults to no limit.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"max_retries": schema.Int64Attribute{
+				Description: "Number of times a request failing with a 429, 500, 502, 503, or 504 status or a refused connection is retried. Defaults to 3. Signing in is retried at most 3 times.",
+				Optional:    true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"retry_wait_min": schema.StringAttribute{
+				Description: "Backoff before the first retry, doubled for each further retry, as a duration such as `500ms`. Defaults to `1s`.",
+				Optional:    true,
+			},
+			"retry_wait_max": schema.StringAttribute{
+				Description: "Longest backoff between retries, as a duration such as `30s`. Defaults to `30s`.",
+				Optional:    true,
+			},
+			"retry_max_elapsed_time": schema.StringAttribute{
+				Description: "Stop retrying transient failures once this much time has passed since the first attempt, as a duration such as `30s` or `2m`. Defaults to no cap.",
+				Optional:    true,
+			},
+			"accept_language": schema.StringAttribute{
+				Description: "BCP 47 language tag, such as `fr-CH`, sent as the Accept-Language header so coffee names are localized where the API supports it.",
+				Optional:    true,
+			},
+			"deduplicate_requests": schema.BoolAttribute{
+				Description: "Send identical HashiCups API reads issued at the same time, such as several data sources reading the coffee catalog, as a single request. Defaults to false.",
+				Optional:    true,
+			},
+			"compress_requests": schema.BoolAttribute{
+				Description: "Gzip encode large request bodies when the HashiCups API reports support for it. Defaults to false.",
+				Optional:    true,
+			},
+			"promo_percent": schema.Float64Attribute{
+				Description: "Discount in percent, from 0 to 100, applied to the discount_price of coffees read by the coffee and coffees data sources. Defaults to 0.",
+				Optional:    true,
+				Validators: []validator.Float64{
+					float64validator.Between(0, 100),
+				},
+			},
+			"price_rounding": schema.StringAttribute{
+				Description: "How discount_price is rounded to cents: `" + priceRoundingHalfUp + "` (default) rounds halves away from zero, " +
+					"`" + priceRoundingHalfEven + "` rounds halves to the even cent, and `" + priceRoundingDown + "` drops fractions of a cent.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(priceRoundingHalfUp, priceRoundingHalfEven, priceRoundingDown),
+				},
+			},
+			"order_item_sort": schema.StringAttribute{
+				Description: "Order of the items written to state when importing an order or reading the order data sources: `" + orderItemSortNone + "` (default) keeps the API order, " +
+					"`" + orderItemSortByCoffeeID + "` sorts by coffee ID, and `" + orderItemSortByName + "` sorts by coffee name. " +
+					"Items of a managed order keep the order of items or from_coffees.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(orderItemSortNone, orderItemSortByCoffeeID, orderItemSortByName),
+				},
+			},
+			"prep_time_mode": schema.StringAttribute{
+				Description: "How hashicups_order estimated_prep_minutes combines the preparation time of each item, the coffee prep time multiplied by its quantity: " +
+					"`" + prepTimeModeSum + "` (default) adds them up for a single barista, and `" + prepTimeModeMax + "` takes the longest for items prepared in parallel.",
+				Optional: true,
+				Validators: []validator.String{
+					stringvalidator.OneOf(prepTimeModeSum, prepTimeModeMax),
+				},
+			},
+			"validate_coffee_ids": schema.BoolAttribute{
+				Description: "Check the coffee IDs of every hashicups_order against the catalog during validation, reading the catalog once and reporting all unknown IDs of an order together. Defaults to false.",
+				Optional:    true,
+			},
+			"default_order_timeout": schema.StringAttribute{
+				Description: "Time allowed for each hashicups_order create, read, update, and delete whose timeouts block does not set one, as a duration such as `5m`. Defaults to `30s`.",
+				Optional:    true,
+			},
+			"create_batch_size": schema.Int64Attribute{
+				Description: "Maximum number of items sent in one request when creating a hashicups_order. A larger order is created with " +
+					"the first items and the rest are added in further requests of at most this many items. Defaults to no limit.",
+				Optional: true,
+				Validators: []validator.Int64{
+					int64validator.AtLeast(1),
+				},
+			},
+			"ordering_hours": schema.StringAttribute{
+				Description: "Daily window in which hashicups_order resources may be created or updated, as `HH:MM-HH:MM` such as `09:00-17:00`. " +
+					"A window ending before it starts spans midnight. Defaults to no restriction.",
+				Optional: true,
+			},
+			"ordering_timezone": schema.StringAttribute{
+				Description: "IANA time zone of ordering_hours, such as `Europe/Paris`. Defaults to `UTC`.",
+				Optional:    true,
+			},
+			"timestamp_format": schema.StringAttribute{
+				Description: "Layout of timestamps written to state, such as hashicups_order last_updated: the name of a standard layout, " +
+					"`RFC3339` (default), `RFC3339Nano`, `RFC1123`, `RFC1123Z`, `RFC822`, `RFC822Z`, `RFC850`, or `DateTime`, " +
+					"or a Go time layout such as `2006-01-02 15:04:05 MST` that keeps the date and time to the second.",
+				Optional: true,
+			},
+			"new_window": schema.StringAttribute{
+				Description: "How long after its created_at a coffee is reported with is_new set, as a duration such as `72h`. Defaults to `720h`, 30 days.",
+				Optional:    true,
+			},
+			"empty_lists_as_null": schema.BoolAttribute{
+				Description: "Return null instead of an empty list for data source lists the API reports as empty, such as coffee ingredients. Defaults to false.",
+				Optional:    true,
+			},
 		},
 	}
 }
 
+// ValidateConfig checks the provider configuration for conflicting
+// attributes: at most one authentication scheme and one CA certificate
+// source, and api_key_header only alongside api_key. It warns when
+// insecure_skip_verify makes a configured CA certificate moot.
+func (p *hashicupsProvider) ValidateConfig(ctx context.Context, req provider.ValidateConfigRequest, resp *provider.ValidateConfigResponse) {
+	var config hashicupsProviderModel
+	diags := req.Config.Get(ctx, &config)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.APIKey.IsNull() && !config.AuthScheme.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key"),
+			"Conflicting HashiCups Authentication",
+			"The api_key attribute cannot be combined with auth_scheme. Remove one of them from the provider configuration.",
+		)
+	}
+
+	if !config.Token.IsNull() && (!config.APIKey.IsNull() || !config.AuthScheme.IsNull()) {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("token"),
+			"Conflicting HashiCups Authentication",
+			"The token attribute cannot be combined with api_key or auth_scheme. Remove one of them from the provider configuration.",
+		)
+	}
+
+	if !config.CACertFile.IsNull() && !config.CACertPEM.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("ca_cert_pem"),
+			"Conflicting HashiCups CA Certificates",
+			"The ca_cert_file and ca_cert_pem attributes cannot both be set. Remove one of them from the provider configuration.",
+		)
+	}
+
+	if config.InsecureSkipVerify.ValueBool() && (!config.CACertFile.IsNull() || !config.CACertPEM.IsNull()) {
+		resp.Diagnostics.AddAttributeWarning(
+			path.Root("insecure_skip_verify"),
+			"HashiCups CA Certificates Ignored",
+			"The server certificate is not verified while insecure_skip_verify is enabled, so the configured ca_cert_file or ca_cert_pem has no effect.",
+		)
+	}
+
+	if config.APIKey.IsNull() && !config.APIKeyHeader.IsNull() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key_header"),
+			"Missing HashiCups API Key",
+			"The api_key_header attribute requires api_key to be set.",
+		)
+	}
+}
+
 // Configure configures the provider.
 func (p *hashicupsProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	ctx = tflog.SetField(ctx, "provider_version", p.version)
+	ctx = tflog.SetField(ctx, "provider_commit", p.commit)
 	tflog.Info(ctx, "Configuring HashiCups provider")
 
 	var config hashicupsProviderModel
@@ -95,6 +605,24 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		)
 	}
 
+	if config.APIKey.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("api_key"),
+			"Unknown HashiCups API Key",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API key. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
+	if config.Token.IsUnknown() {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("token"),
+			"Unknown HashiCups API Token",
+			"The provider cannot create the HashiCups API client as there is an unknown configuration value for the HashiCups API token. "+
+				"Either target apply the source of the value first or set the value statically in the configuration.",
+		)
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -126,7 +654,10 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		)
 	}
 
-	if username == "" {
+	apiKey := config.APIKey.ValueString()
+	token := config.Token.ValueString()
+
+	if username == "" && apiKey == "" && token == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("username"),
 			"Missing HashiCups API Username",
@@ -136,7 +667,7 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		)
 	}
 
-	if password == "" {
+	if password == "" && apiKey == "" && token == "" {
 		resp.Diagnostics.AddAttributeError(
 			path.Root("password"),
 			"Missing HashiCups API Password",
@@ -146,6 +677,99 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 		)
 	}
 
+	var acceptStatus []int
+	if !config.AcceptStatus.IsNull() {
+		diags = config.AcceptStatus.ElementsAs(ctx, &acceptStatus, false)
+		resp.Diagnostics.Append(diags...)
+	}
+
+	retryWaitMin := parseDurationAttribute(config.RetryWaitMin, path.Root("retry_wait_min"), &resp.Diagnostics)
+	retryWaitMax := parseDurationAttribute(config.RetryWaitMax, path.Root("retry_wait_max"), &resp.Diagnostics)
+	if retryWaitMin != nil && retryWaitMax != nil && *retryWaitMin > *retryWaitMax {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("retry_wait_min"),
+			"Invalid Retry Wait",
+			"The retry_wait_min value must not be greater than retry_wait_max.",
+		)
+	}
+
+	var retryMaxElapsedTime time.Duration
+	if elapsed := parseNonNegativeDurationAttribute(config.RetryMaxElapsedTime, path.Root("retry_max_elapsed_time"), &resp.Diagnostics); elapsed != nil {
+		retryMaxElapsedTime = *elapsed
+	}
+
+	rootCAs := loadRootCAs(config, &resp.Diagnostics)
+
+	var proxyURL *url.URL
+	if !config.ProxyURL.IsNull() {
+		var err error
+		proxyURL, err = parseProxyURL(config.ProxyURL.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("proxy_url"),
+				"Invalid Proxy URL",
+				fmt.Sprintf("The proxy_url value %q must be an http, https, or socks5 URL with a host, such as http://proxy.example.com:3128: %s", config.ProxyURL.ValueString(), err),
+			)
+		}
+	}
+
+	var timestampFormat string
+	if !config.TimestampFormat.IsNull() {
+		var err error
+		timestampFormat, err = parseTimestampFormat(config.TimestampFormat.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("timestamp_format"),
+				"Invalid Timestamp Format",
+				fmt.Sprintf("The timestamp_format value %q must be a standard layout name, such as RFC3339, or a Go time layout: %s", config.TimestampFormat.ValueString(), err),
+			)
+		}
+	}
+
+	newWindow := defaultNewWindow
+	if window := parseDurationAttribute(config.NewWindow, path.Root("new_window"), &resp.Diagnostics); window != nil {
+		newWindow = *window
+	}
+
+	var orderingHours *orderingWindow
+	if !config.OrderingHours.IsNull() {
+		location, err := time.LoadLocation(config.OrderingTimezone.ValueString())
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ordering_timezone"),
+				"Invalid Ordering Timezone",
+				fmt.Sprintf("The ordering_timezone value %q is not a known IANA time zone, such as Europe/Paris: %s", config.OrderingTimezone.ValueString(), err),
+			)
+		}
+		orderingHours, err = parseOrderingWindow(config.OrderingHours.ValueString(), location)
+		if err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("ordering_hours"),
+				"Invalid Ordering Hours",
+				fmt.Sprintf("The ordering_hours value %q must be a window such as 09:00-17:00: %s", config.OrderingHours.ValueString(), err),
+			)
+		}
+	}
+
+	var defaultOrderTimeout time.Duration
+	if timeout := parseDurationAttribute(config.DefaultOrderTimeout, path.Root("default_order_timeout"), &resp.Diagnostics); timeout != nil {
+		defaultOrderTimeout = *timeout
+	}
+
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	if !config.AcceptLanguage.IsNull() {
+		if _, err := language.Parse(config.AcceptLanguage.ValueString()); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("accept_language"),
+				"Invalid Accept Language",
+				fmt.Sprintf("The accept_language value %q is not a valid BCP 47 language tag, such as en or fr-CH: %s", config.AcceptLanguage.ValueString(), err),
+			)
+		}
+	}
+
 	if resp.Diagnostics.HasError() {
 		return
 	}
@@ -155,31 +779,432 @@ func (p *hashicupsProvider) Configure(ctx context.Context, req provider.Configur
 	ctx = tflog.SetField(ctx, "hashicups_password", password)
 	ctx = tflog.MaskFieldValuesWithFieldKeys(ctx, "hashicups_password")
 	tflog.Debug(ctx, "Creating HashiCups Client")
+	if config.InsecureSkipVerify.ValueBool() {
+		tflog.Warn(ctx, "TLS certificate verification is disabled for the HashiCups API; do not use insecure_skip_verify in production")
+	}
+
+	// configureClient applies the connection settings. It runs before the
+	// client sends any request, including signing in.
+	configureClient := func(client *Client) {
+		if rootCAs != nil {
+			client.SetRootCAs(rootCAs)
+		}
+		if config.InsecureSkipVerify.ValueBool() {
+			client.SetInsecureSkipVerify(true)
+		}
+		if proxyURL != nil {
+			client.SetProxyURL(proxyURL)
+		}
+		client.APIPath = config.APIPath.ValueString()
+		client.UserAgent = userAgent(p.version, config.UserAgentSuffix.ValueString())
+		client.DisallowUnknownFields = config.DisallowUnknownFields.ValueBool()
+		client.RateLimit = config.RateLimit.ValueFloat64()
+		client.AcceptStatus = acceptStatus
+		client.MaxConcurrentRequests = int(config.MaxConcurrentRequests.ValueInt64())
+		client.CompressRequests = config.CompressRequests.ValueBool()
+		client.DeduplicateRequests = config.DeduplicateRequests.ValueBool()
+		client.RetryMaxElapsedTime = retryMaxElapsedTime
+		if !config.MaxRetries.IsNull() {
+			client.RetryMax = int(config.MaxRetries.ValueInt64())
+		}
+		if retryWaitMin != nil {
+			client.RetryWaitMin = *retryWaitMin
+		}
+		if retryWaitMax != nil {
+			client.RetryWaitMax = *retryWaitMax
+		}
+		client.AcceptLanguage = config.AcceptLanguage.ValueString()
+	}
 
 	// Create the HashiCups API client using the configuration values
-	client, err := NewClient(&host, &username, &password)
+	var client *Client
+	var err error
+	switch {
+	case token != "":
+		client = NewClientWithToken(&host, token)
+		configureClient(client)
+	case apiKey != "":
+		header := defaultAPIKeyHeader
+		if !config.APIKeyHeader.IsNull() {
+			header = config.APIKeyHeader.ValueString()
+		}
+		client = NewClientWithAuthenticator(&host, &APIKeyAuthenticator{Header: header, Key: apiKey})
+		configureClient(client)
+	default:
+		client, err = newAuthenticatedClient(ctx, config.AuthScheme.ValueString(), host, username, password, configureClient)
+	}
 	if err != nil {
+		if summary, detail, ok := classifyConnectionError(err, host); ok {
+			resp.Diagnostics.AddError(summary, detail+"\n\nHashiCups Client Error: "+err.Error()+"\n\n"+p.buildInfo())
+			return
+		}
+
 		resp.Diagnostics.AddError(
 			"Unable to Create HashiCups API Client",
 			"An unexpected error occurred when creating the HashiCups API client. "+
 				"If the error is not clear, please contact the provider developers.\n\n"+
-				"HashiCups Client Error: "+err.Error(),
+				"HashiCups Client Error: "+err.Error()+"\n\n"+p.buildInfo(),
 		)
 		return
 	}
 
-	// Make the HashiCups client available during DataSource and Resource
-	// type Configure methods.
-	resp.DataSourceData = client
-	resp.ResourceData = client
+	data := &providerData{
+		client: client,
+		settings: providerSettings{
+			readOnly:            config.ReadOnly.ValueBool(),
+			checkStock:          config.CheckStock.ValueBool(),
+			emptyListsAsNull:    config.EmptyListsAsNull.ValueBool(),
+			orderItemSort:       config.OrderItemSort.ValueString(),
+			prepTimeMode:        config.PrepTimeMode.ValueString(),
+			validateCoffeeIDs:   config.ValidateCoffeeIDs.ValueBool(),
+			defaultOrderTimeout: defaultOrderTimeout,
+			createBatchSize:     int(config.CreateBatchSize.ValueInt64()),
+			orderingHours:       orderingHours,
+			newWindow:           newWindow,
+			timestampFormat:     timestampFormat,
+			promoPercent:        config.PromoPercent.ValueFloat64(),
+			priceRounding:       config.PriceRounding.ValueString(),
+		},
+		catalog: &coffeeCatalog{client: client},
+	}
+
+	// Make the HashiCups client and settings available during DataSource and
+	// Resource type Configure methods.
+	resp.DataSourceData = data
+	resp.ResourceData = data
 
 	tflog.Info(ctx, "HashiCups provider configured", map[string]any{"success": true})
 }
 
+// parseDurationAttribute parses an optional positive duration attribute,
+// returning nil when it is null or invalid.
+func parseDurationAttribute(value types.String, attributePath path.Path, diags *diag.Diagnostics) *time.Duration {
+	return parseDuration(value, attributePath, false, diags)
+}
+
+// parseNonNegativeDurationAttribute is parseDurationAttribute for attributes
+// where zero is meaningful, such as to disable a limit.
+func parseNonNegativeDurationAttribute(value types.String, attributePath path.Path, diags *diag.Diagnostics) *time.Duration {
+	return parseDuration(value, attributePath, true, diags)
+}
+
+func parseDuration(value types.String, attributePath path.Path, allowZero bool, diags *diag.Diagnostics) *time.Duration {
+	if value.IsNull() {
+		return nil
+	}
+
+	duration, err := time.ParseDuration(value.ValueString())
+	if err != nil || duration < 0 || (duration == 0 && !allowZero) {
+		kind := "positive"
+		if allowZero {
+			kind = "non-negative"
+		}
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid Duration",
+			fmt.Sprintf("The %s value %q must be a %s duration, such as 500ms or 30s.", attributePath, value.ValueString(), kind),
+		)
+		return nil
+	}
+
+	return &duration
+}
+
+// classifyConnectionError returns a diagnostic summary and detail for the
+// common ways of failing to reach host, or false for other errors.
+func classifyConnectionError(err error, host string) (string, string, bool) {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && (apiErr.StatusCode == http.StatusUnauthorized || apiErr.StatusCode == http.StatusForbidden) {
+		return "HashiCups Authentication Failed",
+			fmt.Sprintf("The HashiCups API at %s rejected the credentials with status %d. Check the username and password, or the token or api_key, of the provider configuration.", host, apiErr.StatusCode),
+			true
+	}
+
+	if errors.As(err, &apiErr) && isRetryableError(apiErr) {
+		detail := fmt.Sprintf("The HashiCups API at %s is reachable but unavailable, responding with status %d.", host, apiErr.StatusCode)
+		var retryErr *retryError
+		if errors.As(err, &retryErr) {
+			detail += fmt.Sprintf(" The request was attempted %d times.", retryErr.attempts)
+		}
+		return "HashiCups API Unavailable",
+			detail + " It may be restarting; try again shortly, or raise max_retries and retry_wait_max.",
+			true
+	}
+
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) {
+		return "HashiCups Host Not Found",
+			fmt.Sprintf("The name %q of the HashiCups host %s could not be resolved. Check the host for typos and that it is reachable from this machine's DNS.", dnsErr.Name, host),
+			true
+	}
+
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		return "HashiCups Host Refused Connection",
+			fmt.Sprintf("Nothing is accepting connections at %s. Check that the HashiCups API is running and that the host includes the right port.", host),
+			true
+	}
+
+	var verifyErr *tls.CertificateVerificationError
+	var recordErr tls.RecordHeaderError
+	var unknownAuthorityErr x509.UnknownAuthorityError
+	var hostnameErr x509.HostnameError
+	var invalidErr x509.CertificateInvalidError
+	if errors.As(err, &verifyErr) || errors.As(err, &recordErr) || errors.As(err, &unknownAuthorityErr) ||
+		errors.As(err, &hostnameErr) || errors.As(err, &invalidErr) {
+		return "HashiCups TLS Error",
+			fmt.Sprintf("A TLS connection to %s could not be established. Check that the host uses the right scheme, "+
+				"and set ca_cert_file or ca_cert_pem when its certificate is signed by a private certificate authority.", host),
+			true
+	}
+
+	var netErr net.Error
+	if errors.Is(err, context.DeadlineExceeded) || (errors.As(err, &netErr) && netErr.Timeout()) {
+		return "HashiCups Host Timed Out",
+			fmt.Sprintf("The HashiCups API at %s did not respond in time. Check that the host is reachable and not blocked by a firewall or proxy.", host),
+			true
+	}
+
+	return "", "", false
+}
+
+// parseProxyURL parses a proxy_url, which must name a host and use a proxy
+// scheme the transport supports.
+func parseProxyURL(value string) (*url.URL, error) {
+	proxyURL, err := url.Parse(value)
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyURL.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported scheme %q", proxyURL.Scheme)
+	}
+	if proxyURL.Host == "" {
+		return nil, errors.New("missing host")
+	}
+
+	return proxyURL, nil
+}
+
+// loadRootCAs returns the certificate pool built from ca_cert_file or
+// ca_cert_pem, or nil when neither is set.
+func loadRootCAs(config hashicupsProviderModel, diags *diag.Diagnostics) *x509.CertPool {
+	var pemData []byte
+	attributePath := path.Root("ca_cert_pem")
+	switch {
+	case !config.CACertFile.IsNull():
+		attributePath = path.Root("ca_cert_file")
+		data, err := os.ReadFile(config.CACertFile.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				attributePath,
+				"Unable to Read HashiCups CA Certificate",
+				fmt.Sprintf("The ca_cert_file %q could not be read: %s", config.CACertFile.ValueString(), err),
+			)
+			return nil
+		}
+		pemData = data
+	case !config.CACertPEM.IsNull():
+		pemData = []byte(config.CACertPEM.ValueString())
+	default:
+		return nil
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(pemData) {
+		diags.AddAttributeError(
+			attributePath,
+			"Invalid HashiCups CA Certificate",
+			"No PEM-encoded certificates could be parsed from the value.",
+		)
+		return nil
+	}
+
+	return pool
+}
+
+// Authentication schemes supported by the auth_scheme attribute.
+const (
+	authSchemeToken  = "token"
+	authSchemeBearer = "bearer"
+	authSchemeBasic  = "basic"
+	authSchemeHMAC   = "hmac"
+)
+
+// Item orderings supported by the order_item_sort attribute.
+const (
+	orderItemSortNone       = "none"
+	orderItemSortByCoffeeID = "by_coffee_id"
+	orderItemSortByName     = "by_name"
+)
+
+// Rounding modes supported by the price_rounding attribute.
+const (
+	priceRoundingHalfUp   = "half_up"
+	priceRoundingHalfEven = "half_even"
+	priceRoundingDown     = "down"
+)
+
+// Modes of combining item preparation times supported by the prep_time_mode
+// attribute.
+const (
+	prepTimeModeSum = "sum"
+	prepTimeModeMax = "max"
+)
+
+// defaultAPIKeyHeader is the header the api_key is sent under unless
+// api_key_header is set.
+const defaultAPIKeyHeader = "X-API-Key"
+
+// defaultNewWindow is the new_window used when the provider does not set one.
+const defaultNewWindow = 30 * 24 * time.Hour
+
+// newAuthenticatedClient creates a client authenticating with scheme. The
+// token and bearer schemes sign in with the username and password first,
+// after configure has applied the connection settings.
+func newAuthenticatedClient(ctx context.Context, scheme, host, username, password string, configure func(*Client)) (*Client, error) {
+	client := newClient(&host)
+	if configure != nil {
+		configure(client)
+	}
+
+	switch scheme {
+	case authSchemeBasic:
+		client.Authenticator = &BasicAuthenticator{Username: username, Password: password}
+		return client, nil
+	case authSchemeHMAC:
+		client.Authenticator = &HMACAuthenticator{KeyID: username, Secret: password}
+		return client, nil
+	}
+
+	if err := client.signInWithPassword(ctx, username, password, scheme == authSchemeBearer); err != nil {
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// addReadOnlyError adds the diagnostic returned by resource write operations
+// while the provider is in read-only mode.
+func addReadOnlyError(diags *diag.Diagnostics, action string) {
+	diags.AddError(
+		"HashiCups Provider Is Read-Only",
+		"Unable to "+action+" because the provider is configured with read_only = true. "+
+			"Data sources remain available; disable read_only in the provider configuration to make changes.",
+	)
+}
+
+// parseResourceID parses the numeric id attribute of a resource, naming the
+// resource kind, such as Coffee, in the error.
+func parseResourceID(id types.String, kind string) (int, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	resourceID, err := strconv.Atoi(id.ValueString())
+	if err != nil {
+		diags.AddAttributeError(
+			path.Root("id"),
+			"Invalid HashiCups "+kind+" ID",
+			"The "+strings.ToLower(kind)+" ID must be numeric, got "+strconv.Quote(id.ValueString())+".",
+		)
+	}
+
+	return resourceID, diags
+}
+
+// orderingWindow is a daily window of ordering_hours, as offsets from
+// midnight in its location.
+type orderingWindow struct {
+	start, end time.Duration
+	location   *time.Location
+}
+
+// parseOrderingWindow parses an HH:MM-HH:MM window in location.
+func parseOrderingWindow(value string, location *time.Location) (*orderingWindow, error) {
+	start, end, ok := strings.Cut(value, "-")
+	if !ok {
+		return nil, errors.New("expected a start and end time separated by -")
+	}
+
+	window := &orderingWindow{location: location}
+	for _, bound := range []struct {
+		value  string
+		offset *time.Duration
+	}{{start, &window.start}, {end, &window.end}} {
+		t, err := time.Parse("15:04", strings.TrimSpace(bound.value))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a time of day such as 09:00", bound.value)
+		}
+		*bound.offset = time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	if window.start == window.end {
+		return nil, errors.New("the start and end times must differ")
+	}
+
+	return window, nil
+}
+
+// contains reports whether t falls within the window, including its start
+// and excluding its end.
+func (w *orderingWindow) contains(t time.Time) bool {
+	t = t.In(w.location)
+	offset := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if w.start < w.end {
+		return offset >= w.start && offset < w.end
+	}
+
+	// The window spans midnight.
+	return offset >= w.start || offset < w.end
+}
+
+// String returns the window as configured, with its location.
+func (w *orderingWindow) String() string {
+	midnight := time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
+	return fmt.Sprintf("%s-%s %s", midnight.Add(w.start).Format("15:04"), midnight.Add(w.end).Format("15:04"), w.location)
+}
+
+// addOrderingHoursError adds the diagnostic returned by order writes outside
+// of the provider ordering_hours, returning whether it was added.
+func addOrderingHoursError(diags *diag.Diagnostics, settings providerSettings, action string) bool {
+	if settings.orderingHours == nil {
+		return false
+	}
+
+	now := settings.currentTime()
+	if settings.orderingHours.contains(now) {
+		return false
+	}
+
+	diags.AddError(
+		"Outside HashiCups Ordering Hours",
+		fmt.Sprintf("Unable to %s at %s because orders are only accepted between %s, as set by the provider ordering_hours. "+
+			"Apply again during ordering hours.", action, now.In(settings.orderingHours.location).Format(time.RFC3339), settings.orderingHours),
+	)
+	return true
+}
+
+// addDeprecationWarnings adds a warning for each deprecation notice the
+// client received since the last call. It is deferred by data source and
+// resource operations so each notice is reported once.
+func addDeprecationWarnings(diags *diag.Diagnostics, client *Client) {
+	if client == nil {
+		return
+	}
+
+	for _, notice := range client.DeprecationNotices() {
+		diags.AddWarning("Deprecated HashiCups API Endpoint", notice.Warning())
+	}
+}
+
 // DataSources returns the list of data sources supported by this provider.
 func (p *hashicupsProvider) DataSources(_ context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewCoffeesDataSource,
+		NewCoffeeDataSource,
+		NewIngredientsDataSource,
+		NewOrderDataSource,
+		NewOrdersDataSource,
+		NewRateLimitDataSource,
 	}
 }
 
@@ -187,5 +1212,18 @@ func (p *hashicupsProvider) DataSources(_ context.Context) []func() datasource.D
 func (p *hashicupsProvider) Resources(_ context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewOrderResource,
+		NewComboResource,
+		NewCoffeeResource,
+		NewIngredientResource,
+	}
+}
+
+// Functions returns the list of functions supported by this provider.
+func (p *hashicupsProvider) Functions(_ context.Context) []func() function.Function {
+	return []func() function.Function{
+		NewOrderDiffFunction,
+		NewEstimateOrderCostFunction,
+		NewOrdersSummaryTableFunction,
+		NewOrderTotalFunction,
 	}
 }
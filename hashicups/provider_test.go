@@ -1,8 +1,38 @@
 package hashicups
 
 import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/function"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	pschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	rschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
+	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
 const (
@@ -25,6 +55,1069 @@ var (
 	// CLI command executed to create a provider server to which the CLI can
 	// reattach.
 	testAccProtoV6ProviderFactories = map[string]func() (tfprotov6.ProviderServer, error){
-		"hashicups": providerserver.NewProtocol6WithError(New()),
+		"hashicups": providerserver.NewProtocol6WithError(New("test", "none")()),
 	}
 )
+
+// readTestDataSource runs Read on d with config, a pointer to the data source
+// model or nil for an empty configuration, and returns the response.
+func readTestDataSource(t *testing.T, d datasource.DataSource, config any) *datasource.ReadResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp datasource.SchemaResponse
+	d.Schema(ctx, datasource.SchemaRequest{}, &schemaResp)
+	null := tftypes.NewValue(schemaResp.Schema.Type().TerraformType(ctx), nil)
+
+	raw := tfsdk.State{Schema: schemaResp.Schema, Raw: emptyObject(ctx, schemaResp.Schema.Type())}
+	if config != nil {
+		if diags := raw.Set(ctx, config); diags.HasError() {
+			t.Fatalf("unable to build config: %v", diags)
+		}
+	}
+
+	resp := &datasource.ReadResponse{
+		State: tfsdk.State{Schema: schemaResp.Schema, Raw: null},
+	}
+	d.Read(ctx, datasource.ReadRequest{
+		Config: tfsdk.Config{Schema: schemaResp.Schema, Raw: raw.Raw},
+	}, resp)
+
+	return resp
+}
+
+// emptyObject returns an object value of typ with every attribute null, as
+// Terraform sends for an empty configuration block.
+func emptyObject(ctx context.Context, typ attr.Type) tftypes.Value {
+	objectType := typ.TerraformType(ctx).(tftypes.Object)
+	attributes := make(map[string]tftypes.Value, len(objectType.AttributeTypes))
+	for name, attributeType := range objectType.AttributeTypes {
+		attributes[name] = tftypes.NewValue(attributeType, nil)
+	}
+
+	return tftypes.NewValue(objectType, attributes)
+}
+
+// runTestFunction runs f with args and returns the response.
+func runTestFunction(t *testing.T, f function.Function, args ...attr.Value) *function.RunResponse {
+	t.Helper()
+	ctx := context.Background()
+
+	var definitionResp function.DefinitionResponse
+	f.Definition(ctx, function.DefinitionRequest{}, &definitionResp)
+
+	result, funcErr := definitionResp.Definition.Return.NewResultData(ctx)
+	if funcErr != nil {
+		t.Fatalf("unable to build result: %s", funcErr)
+	}
+
+	resp := &function.RunResponse{Result: result}
+	f.Run(ctx, function.RunRequest{Arguments: function.NewArgumentsData(args)}, resp)
+
+	return resp
+}
+
+// testResourceSchema returns the schema of r.
+func testResourceSchema(t *testing.T, r resource.Resource) rschema.Schema {
+	t.Helper()
+
+	var schemaResp resource.SchemaResponse
+	r.Schema(context.Background(), resource.SchemaRequest{}, &schemaResp)
+	if schemaResp.Diagnostics.HasError() {
+		t.Fatalf("unable to get schema: %v", schemaResp.Diagnostics)
+	}
+
+	return schemaResp.Schema
+}
+
+// testResourceValue returns a raw value for s with every attribute null
+// except those in values, keyed by root attribute name.
+func testResourceValue(t *testing.T, s rschema.Schema, values map[string]any) tftypes.Value {
+	t.Helper()
+	ctx := context.Background()
+
+	plan := tfsdk.Plan{Schema: s, Raw: emptyObject(ctx, s.Type())}
+	for name, value := range values {
+		if diags := plan.SetAttribute(ctx, path.Root(name), value); diags.HasError() {
+			t.Fatalf("unable to set %s: %v", name, diags)
+		}
+	}
+
+	return plan.Raw
+}
+
+func TestProviderSchema(t *testing.T) {
+	server, err := testAccProtoV6ProviderFactories["hashicups"]()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp, err := server.GetProviderSchema(context.Background(), &tfprotov6.GetProviderSchemaRequest{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, d := range resp.Diagnostics {
+		t.Errorf("unexpected diagnostic: %s: %s", d.Summary, d.Detail)
+	}
+}
+
+func TestProviderMetadataVersion(t *testing.T) {
+	var resp provider.MetadataResponse
+	New("1.2.3", "abc1234")().Metadata(context.Background(), provider.MetadataRequest{}, &resp)
+
+	if resp.Version != "1.2.3" {
+		t.Errorf("expected version 1.2.3, got %q", resp.Version)
+	}
+	if resp.TypeName != "hashicups" {
+		t.Errorf("expected type name hashicups, got %q", resp.TypeName)
+	}
+}
+
+// testPlan returns a plan for s holding model.
+func testPlan(t *testing.T, s rschema.Schema, model any) tfsdk.Plan {
+	t.Helper()
+
+	plan := tfsdk.Plan{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	if diags := plan.Set(context.Background(), model); diags.HasError() {
+		t.Fatalf("unable to build plan: %v", diags)
+	}
+
+	return plan
+}
+
+// testState returns a state for s holding model, or a null state when model
+// is nil.
+func testState(t *testing.T, s rschema.Schema, model any) tfsdk.State {
+	t.Helper()
+
+	state := tfsdk.State{Schema: s, Raw: tftypes.NewValue(s.Type().TerraformType(context.Background()), nil)}
+	if model == nil {
+		return state
+	}
+	if diags := state.Set(context.Background(), model); diags.HasError() {
+		t.Fatalf("unable to build state: %v", diags)
+	}
+
+	return state
+}
+
+// runTestResourceCRUD creates planned with r, reads it back, updates it to
+// the plan update returns for the read state, and deletes it, checking that
+// the deleted resource can no longer be read. It returns the models saved
+// after create, read, and update.
+func runTestResourceCRUD[M any](t *testing.T, r resource.Resource, planned M, update func(M) M) (created, read, updated M) {
+	t.Helper()
+	ctx := context.Background()
+	s := testResourceSchema(t, r)
+
+	createResp := &resource.CreateResponse{State: testState(t, s, nil)}
+	r.Create(ctx, resource.CreateRequest{Plan: testPlan(t, s, &planned)}, createResp)
+	if createResp.Diagnostics.HasError() {
+		t.Fatalf("create: %v", createResp.Diagnostics)
+	}
+	createResp.State.Get(ctx, &created)
+
+	readResp := &resource.ReadResponse{State: createResp.State}
+	r.Read(ctx, resource.ReadRequest{State: createResp.State}, readResp)
+	if readResp.Diagnostics.HasError() {
+		t.Fatalf("read: %v", readResp.Diagnostics)
+	}
+	readResp.State.Get(ctx, &read)
+
+	plan := update(read)
+	updateResp := &resource.UpdateResponse{State: readResp.State}
+	r.Update(ctx, resource.UpdateRequest{Plan: testPlan(t, s, &plan), State: readResp.State}, updateResp)
+	if updateResp.Diagnostics.HasError() {
+		t.Fatalf("update: %v", updateResp.Diagnostics)
+	}
+	updateResp.State.Get(ctx, &updated)
+
+	deleteResp := &resource.DeleteResponse{}
+	r.Delete(ctx, resource.DeleteRequest{State: updateResp.State}, deleteResp)
+	if deleteResp.Diagnostics.HasError() {
+		t.Fatalf("delete: %v", deleteResp.Diagnostics)
+	}
+
+	deletedResp := &resource.ReadResponse{State: updateResp.State}
+	r.Read(ctx, resource.ReadRequest{State: updateResp.State}, deletedResp)
+	if !deletedResp.Diagnostics.HasError() {
+		t.Error("expected read of the deleted resource to fail")
+	}
+
+	return created, read, updated
+}
+
+// testProviderConfig returns a provider configuration with every attribute
+// null except those in values, keyed by root attribute name.
+func testProviderConfig(t *testing.T, values map[string]any) tfsdk.Config {
+	t.Helper()
+	ctx := context.Background()
+
+	var schemaResp provider.SchemaResponse
+	New("test", "none")().Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+
+	state := tfsdk.State{Schema: schemaResp.Schema, Raw: emptyObject(ctx, schemaResp.Schema.Type())}
+	for name, value := range values {
+		if diags := state.SetAttribute(ctx, path.Root(name), value); diags.HasError() {
+			t.Fatalf("unable to set %s: %v", name, diags)
+		}
+	}
+
+	return tfsdk.Config{Schema: schemaResp.Schema, Raw: state.Raw}
+}
+
+func TestProviderValidateConfigAPIKey(t *testing.T) {
+	tests := map[string]struct {
+		values        map[string]any
+		expectError   bool
+		expectWarning bool
+	}{
+		"api key":              {values: map[string]any{"api_key": "secret", "api_key_header": "X-Gateway-Key"}},
+		"api key with bearer":  {values: map[string]any{"api_key": "secret", "auth_scheme": authSchemeBearer}, expectError: true},
+		"api key with basic":   {values: map[string]any{"api_key": "secret", "auth_scheme": authSchemeBasic}, expectError: true},
+		"header without key":   {values: map[string]any{"api_key_header": "X-Gateway-Key"}, expectError: true},
+		"token with api key":   {values: map[string]any{"token": "secret", "api_key": "secret"}, expectError: true},
+		"token with scheme":    {values: map[string]any{"token": "secret", "auth_scheme": authSchemeToken}, expectError: true},
+		"ca cert file and pem": {values: map[string]any{"ca_cert_file": "ca.pem", "ca_cert_pem": "pem"}, expectError: true},
+		"insecure with ca":     {values: map[string]any{"insecure_skip_verify": true, "ca_cert_pem": "pem"}, expectWarning: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			p := New("test", "none")().(provider.ProviderWithValidateConfig)
+
+			var resp provider.ValidateConfigResponse
+			p.ValidateConfig(context.Background(), provider.ValidateConfigRequest{Config: testProviderConfig(t, test.values)}, &resp)
+			if resp.Diagnostics.HasError() != test.expectError {
+				t.Errorf("expected error %t, got %v", test.expectError, resp.Diagnostics)
+			}
+			if got := resp.Diagnostics.WarningsCount() > 0; got != test.expectWarning {
+				t.Errorf("expected warning %t, got %v", test.expectWarning, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureAPIKey(t *testing.T) {
+	var header http.Header
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Clone()
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	var resp provider.ConfigureResponse
+	New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+		Config: testProviderConfig(t, map[string]any{
+			"host":           client.HostURL,
+			"api_key":        "secret",
+			"api_key_header": "X-Gateway-Key",
+		}),
+	}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	if _, err := resp.ResourceData.(*providerData).client.GetCoffees(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Get("X-Gateway-Key"); got != "secret" {
+		t.Errorf("expected API key under X-Gateway-Key, got %q", got)
+	}
+	if got := header.Get("Authorization"); got != "" {
+		t.Errorf("expected no Authorization header, got %q", got)
+	}
+}
+
+func TestProviderConfigureToken(t *testing.T) {
+	var paths []string
+	var authorization string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		paths = append(paths, r.URL.Path)
+		authorization = r.Header.Get("Authorization")
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	var resp provider.ConfigureResponse
+	New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+		Config: testProviderConfig(t, map[string]any{
+			"host":     client.HostURL,
+			"token":    "gateway-token",
+			"username": "education",
+			"password": "test123",
+		}),
+	}, &resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("unexpected error: %v", resp.Diagnostics)
+	}
+
+	if _, err := resp.ResourceData.(*providerData).client.GetCoffees(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range paths {
+		if p == "/signin" {
+			t.Errorf("expected no sign-in request, got requests to %v", paths)
+		}
+	}
+	if authorization != "Bearer gateway-token" {
+		t.Errorf("expected bearer token, got %q", authorization)
+	}
+}
+
+func TestProviderConfigureRetries(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	tests := map[string]struct {
+		config      map[string]any
+		expectError bool
+	}{
+		"valid": {
+			config: map[string]any{"max_retries": int64(5), "retry_wait_min": "10ms", "retry_wait_max": "2s"},
+		},
+		"invalid duration": {
+			config:      map[string]any{"retry_wait_min": "soon"},
+			expectError: true,
+		},
+		"minimum above maximum": {
+			config:      map[string]any{"retry_wait_min": "5s", "retry_wait_max": "1s"},
+			expectError: true,
+		},
+		"zero max elapsed time": {
+			config: map[string]any{"max_retries": int64(5), "retry_wait_min": "10ms", "retry_wait_max": "2s", "retry_max_elapsed_time": "0s"},
+		},
+		"negative max elapsed time": {
+			config:      map[string]any{"retry_max_elapsed_time": "-1s"},
+			expectError: true,
+		},
+		"zero default order timeout": {
+			config:      map[string]any{"default_order_timeout": "0s"},
+			expectError: true,
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]any{"host": client.HostURL, "api_key": "secret"}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, config),
+			}, &resp)
+			if test.expectError {
+				if !resp.Diagnostics.HasError() {
+					t.Error("expected error, got none")
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			configured := resp.ResourceData.(*providerData).client
+			if configured.RetryMax != 5 || configured.RetryWaitMin != 10*time.Millisecond || configured.RetryWaitMax != 2*time.Second {
+				t.Errorf("unexpected retry settings: max %d, wait %s to %s", configured.RetryMax, configured.RetryWaitMin, configured.RetryWaitMax)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureCACert(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(server.Close)
+
+	caPEM := string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw}))
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	if err := os.WriteFile(caFile, []byte(caPEM), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		config               map[string]any
+		expectErrorAttribute string
+		expectTLSError       bool
+	}{
+		"inline pem": {
+			config: map[string]any{"ca_cert_pem": caPEM},
+		},
+		"file": {
+			config: map[string]any{"ca_cert_file": caFile},
+		},
+		"untrusted": {
+			config:         map[string]any{},
+			expectTLSError: true,
+		},
+		"missing file": {
+			config:               map[string]any{"ca_cert_file": filepath.Join(t.TempDir(), "missing.pem")},
+			expectErrorAttribute: "ca_cert_file",
+		},
+		"invalid pem": {
+			config:               map[string]any{"ca_cert_pem": "not a certificate"},
+			expectErrorAttribute: "ca_cert_pem",
+		},
+		"insecure skip verify": {
+			config: map[string]any{"insecure_skip_verify": true},
+		},
+		"insecure skip verify with ca": {
+			config: map[string]any{"insecure_skip_verify": true, "ca_cert_pem": caPEM},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]any{"host": server.URL, "api_key": "secret"}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, config),
+			}, &resp)
+			if test.expectErrorAttribute != "" {
+				errs := resp.Diagnostics.Errors()
+				if len(errs) != 1 {
+					t.Fatalf("expected one error, got %v", resp.Diagnostics)
+				}
+				if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root(test.expectErrorAttribute)) {
+					t.Errorf("expected error at %s, got %v", test.expectErrorAttribute, errs[0])
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			client := resp.ResourceData.(*providerData).client
+			client.RetryMax = 0
+			_, err := client.GetCoffees(context.Background())
+			if test.expectTLSError && err == nil {
+				t.Error("expected certificate error, got none")
+			}
+			if !test.expectTLSError && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureAPIPath(t *testing.T) {
+	tests := map[string]struct {
+		host           string
+		apiPath        string
+		expectedPrefix string
+	}{
+		"unset":           {},
+		"prefix":          {apiPath: "/hashicups/v1", expectedPrefix: "/hashicups/v1"},
+		"extra slashes":   {host: "/", apiPath: "//hashicups/v1/", expectedPrefix: "/hashicups/v1"},
+		"without leading": {apiPath: "hashicups", expectedPrefix: "/hashicups"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var paths []string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				paths = append(paths, r.URL.Path)
+				_, _ = w.Write([]byte(`{"token":"secret"}`))
+			})
+			config := map[string]any{"host": client.HostURL + test.host, "username": "education", "password": "test123"}
+			if test.apiPath != "" {
+				config["api_path"] = test.apiPath
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, config),
+			}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			configured := resp.ResourceData.(*providerData).client
+			_, _ = configured.GetCoffees(context.Background())
+			// Signing in during configure uses the prefix too.
+			if paths[0] != test.expectedPrefix+"/signin" || paths[len(paths)-1] != test.expectedPrefix+"/coffees" {
+				t.Errorf("expected requests under %q, got %v", test.expectedPrefix, paths)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureUserAgent(t *testing.T) {
+	tests := map[string]struct {
+		suffix   string
+		expected string
+	}{
+		"default":     {expected: "terraform-provider-hashicups/1.2.3 (terraform-plugin-framework)"},
+		"with suffix": {suffix: "ci-pipeline/4", expected: "terraform-provider-hashicups/1.2.3 (terraform-plugin-framework) ci-pipeline/4"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var userAgents []string
+			client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				userAgents = append(userAgents, r.UserAgent())
+				_, _ = w.Write([]byte(`{"token":"secret"}`))
+			})
+			config := map[string]any{"host": client.HostURL, "username": "education", "password": "test123"}
+			if test.suffix != "" {
+				config["user_agent_suffix"] = test.suffix
+			}
+
+			var resp provider.ConfigureResponse
+			New("1.2.3", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, config),
+			}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			_, _ = resp.ResourceData.(*providerData).client.GetCoffees(context.Background())
+
+			if len(userAgents) < 2 {
+				t.Fatalf("expected sign-in and coffee requests, got %d requests", len(userAgents))
+			}
+			for _, userAgent := range userAgents {
+				if userAgent != test.expected {
+					t.Errorf("expected User-Agent %q, got %q", test.expected, userAgent)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderConfigureProxyURL(t *testing.T) {
+	var proxiedHost string
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		proxiedHost = r.URL.Host
+		_, _ = w.Write([]byte(`[]`))
+	}))
+	t.Cleanup(proxy.Close)
+
+	tests := map[string]struct {
+		proxyURL    string
+		expectError bool
+	}{
+		"http proxy":     {proxyURL: proxy.URL},
+		"missing scheme": {proxyURL: "proxy.example.com:3128", expectError: true},
+		"unsupported":    {proxyURL: "ftp://proxy.example.com", expectError: true},
+		"missing host":   {proxyURL: "http://", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, map[string]any{
+					"host":      "http://hashicups.example.com",
+					"api_key":   "secret",
+					"proxy_url": test.proxyURL,
+				}),
+			}, &resp)
+			if test.expectError {
+				errs := resp.Diagnostics.Errors()
+				if len(errs) != 1 {
+					t.Fatalf("expected one error, got %v", resp.Diagnostics)
+				}
+				if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root("proxy_url")) {
+					t.Errorf("expected error at proxy_url, got %v", errs[0])
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			client := resp.ResourceData.(*providerData).client
+			if _, err := client.GetCoffees(context.Background()); err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+			if proxiedHost != "hashicups.example.com" {
+				t.Errorf("expected the request to hashicups.example.com to go through the proxy, got host %q", proxiedHost)
+			}
+		})
+	}
+}
+
+func TestProviderConfigurePromoPercent(t *testing.T) {
+	ctx := context.Background()
+	var schemaResp provider.SchemaResponse
+	New("test", "none")().Schema(ctx, provider.SchemaRequest{}, &schemaResp)
+	promoPercent := schemaResp.Schema.Attributes["promo_percent"].(pschema.Float64Attribute)
+
+	tests := map[string]struct {
+		promoPercent float64
+		expectError  bool
+	}{
+		"25 percent":   {promoPercent: 25},
+		"free":         {promoPercent: 100},
+		"negative":     {promoPercent: -5, expectError: true},
+		"over hundred": {promoPercent: 150, expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var diags diag.Diagnostics
+			for _, v := range promoPercent.Validators {
+				resp := &validator.Float64Response{}
+				v.ValidateFloat64(ctx, validator.Float64Request{
+					Path:        path.Root("promo_percent"),
+					ConfigValue: types.Float64Value(test.promoPercent),
+				}, resp)
+				diags.Append(resp.Diagnostics...)
+			}
+			if test.expectError {
+				if diags.ErrorsCount() != 1 {
+					t.Errorf("expected one error, got %v", diags)
+				}
+				return
+			}
+			if diags.HasError() {
+				t.Fatalf("unexpected validation error: %v", diags)
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(ctx, provider.ConfigureRequest{
+				Config: testProviderConfig(t, map[string]any{
+					"host":          "http://localhost:19090",
+					"api_key":       "secret",
+					"promo_percent": test.promoPercent,
+				}),
+			}, &resp)
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+			if got := resp.DataSourceData.(*providerData).settings.promoPercent; got != test.promoPercent {
+				t.Errorf("expected promo percent %v, got %v", test.promoPercent, got)
+			}
+		})
+	}
+}
+
+func TestParseTimestampFormat(t *testing.T) {
+	tests := map[string]struct {
+		format         string
+		expectedLayout string
+		expectError    bool
+	}{
+		"standard name": {format: "RFC850", expectedLayout: time.RFC850},
+		"custom layout": {format: "2006-01-02 15:04:05 MST", expectedLayout: "2006-01-02 15:04:05 MST"},
+		"date only":     {format: "2006-01-02", expectError: true},
+		"no verbs":      {format: "timestamp", expectError: true},
+		"unknown name":  {format: "ISO8601", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			layout, err := parseTimestampFormat(test.format)
+			if (err != nil) != test.expectError {
+				t.Fatalf("expected error %t, got %v", test.expectError, err)
+			}
+			if layout != test.expectedLayout {
+				t.Errorf("expected layout %q, got %q", test.expectedLayout, layout)
+			}
+		})
+	}
+}
+
+func TestParseOrderingWindow(t *testing.T) {
+	at := func(hour, minute int) time.Time {
+		return time.Date(2030, 1, 1, hour, minute, 0, 0, time.UTC)
+	}
+
+	tests := map[string]struct {
+		value       string
+		expectError bool
+		inside      []time.Time
+		outside     []time.Time
+	}{
+		"business hours": {
+			value:   "09:00-17:00",
+			inside:  []time.Time{at(9, 0), at(12, 30), at(16, 59)},
+			outside: []time.Time{at(8, 59), at(17, 0), at(23, 0)},
+		},
+		"spans midnight": {
+			value:   "22:00 - 06:00",
+			inside:  []time.Time{at(22, 0), at(0, 0), at(5, 59)},
+			outside: []time.Time{at(6, 0), at(12, 0), at(21, 59)},
+		},
+		"missing end":  {value: "09:00", expectError: true},
+		"invalid time": {value: "9am-5pm", expectError: true},
+		"out of range": {value: "09:00-25:00", expectError: true},
+		"empty window": {value: "09:00-09:00", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			window, err := parseOrderingWindow(test.value, time.UTC)
+			if test.expectError {
+				if err == nil {
+					t.Error("expected error, got none")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			for _, now := range test.inside {
+				if !window.contains(now) {
+					t.Errorf("expected %s to be inside %s", now.Format("15:04"), window)
+				}
+			}
+			for _, now := range test.outside {
+				if window.contains(now) {
+					t.Errorf("expected %s to be outside %s", now.Format("15:04"), window)
+				}
+			}
+		})
+	}
+}
+
+func TestProviderConfigureOrderingHours(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	tests := map[string]struct {
+		config          map[string]any
+		expectAttribute string
+	}{
+		"valid":            {config: map[string]any{"ordering_hours": "09:00-17:00", "ordering_timezone": "UTC"}},
+		"invalid hours":    {config: map[string]any{"ordering_hours": "always"}, expectAttribute: "ordering_hours"},
+		"invalid timezone": {config: map[string]any{"ordering_hours": "09:00-17:00", "ordering_timezone": "Mars/Olympus_Mons"}, expectAttribute: "ordering_timezone"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			config := map[string]any{"host": client.HostURL, "api_key": "secret"}
+			for key, value := range test.config {
+				config[key] = value
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, config),
+			}, &resp)
+			if test.expectAttribute == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				if resp.ResourceData.(*providerData).settings.orderingHours == nil {
+					t.Error("expected ordering hours to be set")
+				}
+				return
+			}
+
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 {
+				t.Fatalf("expected one error, got %v", resp.Diagnostics)
+			}
+			if d, ok := errs[0].(diag.DiagnosticWithPath); !ok || !d.Path().Equal(path.Root(test.expectAttribute)) {
+				t.Errorf("expected error at %s, got %v", test.expectAttribute, errs[0])
+			}
+		})
+	}
+}
+
+func TestClassifyConnectionError(t *testing.T) {
+	tests := map[string]struct {
+		err           error
+		expectSummary string
+	}{
+		"dns": {
+			err:           &url.Error{Op: "Post", URL: "http://hashicups.invalid/signin", Err: &net.OpError{Op: "dial", Err: &net.DNSError{Name: "hashicups.invalid", IsNotFound: true}}},
+			expectSummary: "HashiCups Host Not Found",
+		},
+		"connection refused": {
+			err:           &url.Error{Op: "Post", URL: "http://localhost:1/signin", Err: &net.OpError{Op: "dial", Err: os.NewSyscallError("connect", syscall.ECONNREFUSED)}},
+			expectSummary: "HashiCups Host Refused Connection",
+		},
+		"tls": {
+			err:           &url.Error{Op: "Post", URL: "https://localhost/signin", Err: &tls.CertificateVerificationError{Err: x509.UnknownAuthorityError{}}},
+			expectSummary: "HashiCups TLS Error",
+		},
+		"timeout": {
+			err:           &url.Error{Op: "Post", URL: "http://localhost/signin", Err: context.DeadlineExceeded},
+			expectSummary: "HashiCups Host Timed Out",
+		},
+		"unauthorized": {
+			err:           newAPIError(http.StatusUnauthorized, []byte("invalid credentials")),
+			expectSummary: "HashiCups Authentication Failed",
+		},
+		"other": {
+			err: errors.New("unexpected response"),
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			summary, detail, ok := classifyConnectionError(test.err, "http://localhost")
+			if ok != (test.expectSummary != "") || summary != test.expectSummary {
+				t.Fatalf("expected summary %q, got %q (classified %t)", test.expectSummary, summary, ok)
+			}
+			if ok && !strings.Contains(detail, "http://localhost") {
+				t.Errorf("expected the detail to name the host, got %q", detail)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureConnectionErrors(t *testing.T) {
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	untrusted := httptest.NewTLSServer(http.NotFoundHandler())
+	t.Cleanup(untrusted.Close)
+
+	unauthorized := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	t.Cleanup(unauthorized.Close)
+
+	unavailable := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	t.Cleanup(unavailable.Close)
+
+	tests := map[string]struct {
+		host          string
+		expectSummary string
+	}{
+		"connection refused": {host: closed.URL, expectSummary: "HashiCups Host Refused Connection"},
+		"tls":                {host: untrusted.URL, expectSummary: "HashiCups TLS Error"},
+		"unauthorized":       {host: unauthorized.URL, expectSummary: "HashiCups Authentication Failed"},
+		"unavailable":        {host: unavailable.URL, expectSummary: "HashiCups API Unavailable"},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var resp provider.ConfigureResponse
+			New("1.2.3", "abc123")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, map[string]any{
+					"host":           test.host,
+					"username":       "education",
+					"password":       "test123",
+					"retry_wait_min": "1ms",
+					"retry_wait_max": "1ms",
+				}),
+			}, &resp)
+
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 || errs[0].Summary() != test.expectSummary {
+				t.Fatalf("expected a %q error, got %v", test.expectSummary, resp.Diagnostics)
+			}
+			if !strings.Contains(errs[0].Detail(), "Provider version: 1.2.3, commit: abc123") {
+				t.Errorf("expected the build info in the detail, got %q", errs[0].Detail())
+			}
+		})
+	}
+}
+
+func TestProviderConfigureSignInRetry(t *testing.T) {
+	tests := map[string]struct {
+		statuses       []int
+		maxRetries     int64
+		expectAttempts int
+		expectSummary  string
+	}{
+		"recovers": {statuses: []int{http.StatusServiceUnavailable, http.StatusBadGateway}, expectAttempts: 3},
+		"bounded": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			maxRetries:     10,
+			expectAttempts: maxSignInRetries + 1,
+			expectSummary:  "HashiCups API Unavailable",
+		},
+		"unauthorized": {statuses: []int{http.StatusUnauthorized}, expectAttempts: 1, expectSummary: "HashiCups Authentication Failed"},
+		"unavailable": {
+			statuses:       []int{http.StatusServiceUnavailable, http.StatusServiceUnavailable, http.StatusServiceUnavailable},
+			expectAttempts: 3,
+			expectSummary:  "HashiCups API Unavailable",
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var attempts int
+			var keys []string
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				attempts++
+				if key := r.Header.Get(idempotencyKeyHeader); key != "" {
+					keys = append(keys, key)
+				}
+				if attempts <= len(test.statuses) {
+					w.WriteHeader(test.statuses[attempts-1])
+					return
+				}
+				_, _ = w.Write([]byte(`{"UserID":1,"Username":"education","token":"secret"}`))
+			}))
+			t.Cleanup(server.Close)
+
+			maxRetries := int64(2)
+			if test.maxRetries > 0 {
+				maxRetries = test.maxRetries
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, map[string]any{
+					"host":           server.URL,
+					"username":       "education",
+					"password":       "test123",
+					"max_retries":    maxRetries,
+					"retry_wait_min": "1ms",
+					"retry_wait_max": "1ms",
+				}),
+			}, &resp)
+
+			if attempts != test.expectAttempts {
+				t.Errorf("expected %d sign-in attempts, got %d", test.expectAttempts, attempts)
+			}
+			if len(keys) > 0 {
+				t.Errorf("expected no idempotency key on sign-in, got %q", keys)
+			}
+			if test.expectSummary == "" {
+				if resp.Diagnostics.HasError() {
+					t.Fatalf("unexpected error: %v", resp.Diagnostics)
+				}
+				return
+			}
+			errs := resp.Diagnostics.Errors()
+			if len(errs) != 1 || errs[0].Summary() != test.expectSummary {
+				t.Errorf("expected a %q error, got %v", test.expectSummary, resp.Diagnostics)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureAcceptLanguage(t *testing.T) {
+	tests := map[string]struct {
+		acceptLanguage string
+		expectError    bool
+	}{
+		"valid":   {acceptLanguage: "fr-CH"},
+		"invalid": {acceptLanguage: "not a language", expectError: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, map[string]any{
+					"host":            "http://localhost:19090",
+					"api_key":         "secret",
+					"accept_language": test.acceptLanguage,
+				}),
+			}, &resp)
+
+			if resp.Diagnostics.HasError() != test.expectError {
+				t.Fatalf("expected error %t, got %v", test.expectError, resp.Diagnostics)
+			}
+			if !test.expectError && resp.ResourceData.(*providerData).client.AcceptLanguage != test.acceptLanguage {
+				t.Errorf("expected client Accept-Language %q", test.acceptLanguage)
+			}
+		})
+	}
+}
+
+func TestProviderConfigureEnvironment(t *testing.T) {
+	var username, password string
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ = r.BasicAuth()
+		_, _ = w.Write([]byte(`[]`))
+	})
+
+	tests := map[string]struct {
+		env              map[string]string
+		config           map[string]any
+		expectUsername   string
+		expectErrorPaths []path.Path
+	}{
+		"environment only": {
+			env:            map[string]string{"HASHICUPS_HOST": client.HostURL, "HASHICUPS_USERNAME": "env-user", "HASHICUPS_PASSWORD": "env-pass"},
+			config:         map[string]any{"auth_scheme": authSchemeBasic},
+			expectUsername: "env-user",
+		},
+		"configuration takes precedence": {
+			env:            map[string]string{"HASHICUPS_HOST": "http://env.invalid", "HASHICUPS_USERNAME": "env-user", "HASHICUPS_PASSWORD": "env-pass"},
+			config:         map[string]any{"auth_scheme": authSchemeBasic, "host": client.HostURL, "username": "config-user"},
+			expectUsername: "config-user",
+		},
+		"missing values": {
+			env:              map[string]string{"HASHICUPS_HOST": "", "HASHICUPS_USERNAME": "", "HASHICUPS_PASSWORD": ""},
+			config:           map[string]any{"auth_scheme": authSchemeBasic},
+			expectErrorPaths: []path.Path{path.Root("host"), path.Root("username"), path.Root("password")},
+		},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			for key, value := range test.env {
+				t.Setenv(key, value)
+			}
+
+			var resp provider.ConfigureResponse
+			New("test", "none")().Configure(context.Background(), provider.ConfigureRequest{
+				Config: testProviderConfig(t, test.config),
+			}, &resp)
+
+			if len(test.expectErrorPaths) > 0 {
+				var paths []path.Path
+				for _, d := range resp.Diagnostics.Errors() {
+					if d, ok := d.(diag.DiagnosticWithPath); ok {
+						paths = append(paths, d.Path())
+					}
+				}
+				if !reflect.DeepEqual(paths, test.expectErrorPaths) {
+					t.Errorf("expected errors at %v, got %v", test.expectErrorPaths, resp.Diagnostics)
+				}
+				return
+			}
+			if resp.Diagnostics.HasError() {
+				t.Fatalf("unexpected error: %v", resp.Diagnostics)
+			}
+
+			if _, err := resp.ResourceData.(*providerData).client.GetCoffees(context.Background()); err != nil {
+				t.Fatal(err)
+			}
+			if username != test.expectUsername || password != "env-pass" {
+				t.Errorf("expected credentials %s:env-pass, got %s:%s", test.expectUsername, username, password)
+			}
+		})
+	}
+}
+
+func TestCoffeeCatalogRetriesFailedReads(t *testing.T) {
+	var requests int
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if requests == 1 {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		_, _ = w.Write([]byte(`[{"id":1,"name":"HCP Aeropress"}]`))
+	})
+	catalog := &coffeeCatalog{client: client}
+
+	if _, err := catalog.coffeeIDs(context.Background()); err == nil {
+		t.Fatal("expected the first read to fail")
+	}
+
+	// The failure is not cached, and the successful read is.
+	for i := 0; i < 2; i++ {
+		coffees, err := catalog.coffeesByID(context.Background())
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if coffees[1].Name != "HCP Aeropress" {
+			t.Errorf("unexpected catalog: %+v", coffees)
+		}
+	}
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+}
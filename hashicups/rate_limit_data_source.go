@@ -0,0 +1,82 @@
+package hashicups
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// Ensure the implementation satisfies the expected interfaces.
+var (
+	_ datasource.DataSource              = &rateLimitDataSource{}
+	_ datasource.DataSourceWithConfigure = &rateLimitDataSource{}
+)
+
+func NewRateLimitDataSource() datasource.DataSource {
+	return &rateLimitDataSource{}
+}
+
+type rateLimitDataSource struct {
+	client *Client
+}
+
+// rateLimitDataSourceModel maps the data source schema data.
+type rateLimitDataSourceModel struct {
+	ID        types.String `tfsdk:"id"`
+	Remaining types.Int64  `tfsdk:"remaining"`
+	Reset     types.Int64  `tfsdk:"reset"`
+}
+
+func (d *rateLimitDataSource) Metadata(_ context.Context, request datasource.MetadataRequest, response *datasource.MetadataResponse) {
+	response.TypeName = request.ProviderTypeName + "_rate_limit"
+}
+
+// Schema defines the schema for the data source.
+func (d *rateLimitDataSource) Schema(_ context.Context, _ datasource.SchemaRequest, response *datasource.SchemaResponse) {
+	response.Schema = schema.Schema{
+		Description: "Reports the rate limit headers of the most recent HashiCups API response. " +
+			"No request is made, so use depends_on to read it after the resources or data sources of interest.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed:    true,
+				Description: "Placeholder identifier attribute.",
+			},
+			"remaining": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Value of the X-RateLimit-Remaining header. Null when the server does not send it.",
+			},
+			"reset": schema.Int64Attribute{
+				Computed:    true,
+				Description: "Value of the X-RateLimit-Reset header, as sent by the server. Null when the server does not send it.",
+			},
+		},
+	}
+}
+
+// Read refreshes the Terraform state with the latest rate limit headers.
+func (d *rateLimitDataSource) Read(ctx context.Context, _ datasource.ReadRequest, response *datasource.ReadResponse) {
+	state := rateLimitDataSourceModel{
+		ID:        types.StringValue("placeholder"),
+		Remaining: types.Int64Null(),
+		Reset:     types.Int64Null(),
+	}
+
+	if d.client != nil {
+		status := d.client.LastRateLimit()
+		state.Remaining = types.Int64PointerValue(status.Remaining)
+		state.Reset = types.Int64PointerValue(status.Reset)
+	}
+
+	diags := response.State.Set(ctx, &state)
+	response.Diagnostics.Append(diags...)
+}
+
+func (d *rateLimitDataSource) Configure(_ context.Context, request datasource.ConfigureRequest, _ *datasource.ConfigureResponse) {
+	if request.ProviderData == nil {
+		return
+	}
+
+	d.client = request.ProviderData.(*providerData).client
+}
@@ -0,0 +1,62 @@
+package hashicups
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRateLimitDataSource(t *testing.T) {
+	var calls int32
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch atomic.AddInt32(&calls, 1) {
+		case 1:
+			w.Header().Set("X-RateLimit-Remaining", "42")
+			w.Header().Set("X-RateLimit-Reset", "1735689600")
+		case 2:
+			w.Header().Set("X-RateLimit-Remaining", "41")
+		}
+		_, _ = w.Write([]byte(`[]`))
+	})
+	d := &rateLimitDataSource{client: client}
+
+	read := func() rateLimitDataSourceModel {
+		t.Helper()
+		resp := readTestDataSource(t, d, nil)
+		if resp.Diagnostics.HasError() {
+			t.Fatalf("unexpected error: %v", resp.Diagnostics)
+		}
+
+		var state rateLimitDataSourceModel
+		resp.State.Get(context.Background(), &state)
+		return state
+	}
+
+	if state := read(); !state.Remaining.IsNull() || !state.Reset.IsNull() {
+		t.Errorf("expected nulls before any request, got %+v", state)
+	}
+
+	if _, err := client.GetCoffees(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state := read(); state.Remaining.ValueInt64() != 42 || state.Reset.ValueInt64() != 1735689600 {
+		t.Errorf("expected remaining 42 and reset 1735689600, got %+v", state)
+	}
+
+	// A response with only one header replaces both values.
+	if _, err := client.GetCoffees(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state := read(); state.Remaining.ValueInt64() != 41 || !state.Reset.IsNull() {
+		t.Errorf("expected remaining 41 and null reset, got %+v", state)
+	}
+
+	// A response without the headers keeps the most recent values.
+	if _, err := client.GetCoffees(context.Background()); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if state := read(); state.Remaining.ValueInt64() != 41 {
+		t.Errorf("expected remaining 41 to be kept, got %+v", state)
+	}
+}
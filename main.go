@@ -2,12 +2,20 @@ package main
 
 import (
 	"context"
+
 	"github.com/hashicorp/terraform-plugin-framework/providerserver"
 	"terraform-provider-hashicups-pf/hashicups"
 )
 
+var (
+	// version and commit are set at build time, for example:
+	//   go build -ldflags "-X main.version=0.1.0 -X main.commit=$(git rev-parse --short HEAD)"
+	version string = "dev"
+	commit  string = "none"
+)
+
 func main() {
-	providerserver.Serve(context.Background(), hashicups.New, providerserver.ServeOpts{
+	providerserver.Serve(context.Background(), hashicups.New(version, commit), providerserver.ServeOpts{
 		Address: "hashicorp.com/edu/hashicups-pf",
 	})
 }